@@ -2,6 +2,7 @@ package fixedwidth
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 )
 
@@ -27,6 +28,7 @@ type mixedData struct {
 var mixedDataInstance = mixedData{"foo", stringp("foo"), 42, int64p(42), 42, int32p(42), 42, int16p(42), 42, int8p(42), 4.2, float64p(4.2), 4.2, false, true} //,float32p(4.2)}
 
 func BenchmarkUnmarshal_MixedData_1(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`       foo       foo        42        42        42        42        42        42        42        42       4.2       4.2       4.2       4.2     false         t`)
 	var v mixedData
 	b.ResetTimer()
@@ -36,6 +38,7 @@ func BenchmarkUnmarshal_MixedData_1(b *testing.B) {
 }
 
 func BenchmarkUnmarshal_MixedData_1000(b *testing.B) {
+	b.ReportAllocs()
 	data := bytes.Repeat([]byte(`       foo       foo        42        42        42        42        42        42        42        42       4.2       4.2       4.2       4.2     false         t`+"\n"), 100)
 	var v []mixedData
 	b.ResetTimer()
@@ -45,6 +48,7 @@ func BenchmarkUnmarshal_MixedData_1000(b *testing.B) {
 }
 
 func BenchmarkUnmarshal_MixedData_100000(b *testing.B) {
+	b.ReportAllocs()
 	data := bytes.Repeat([]byte(`       foo       foo        42        42        42        42        42        42        42        42       4.2       4.2       4.2       4.2     false         t`+"\n"), 10000)
 	var v []mixedData
 	b.ResetTimer()
@@ -54,6 +58,7 @@ func BenchmarkUnmarshal_MixedData_100000(b *testing.B) {
 }
 
 func BenchmarkDecode_CodePoints_MixedData_1_Ascii(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`       foo       foo        42        42        42        42        42        42        42        42       4.2       4.2       4.2       4.2     false         t`)
 	var v mixedData
 	for i := 0; i < b.N; i++ {
@@ -64,6 +69,7 @@ func BenchmarkDecode_CodePoints_MixedData_1_Ascii(b *testing.B) {
 }
 
 func BenchmarkDecode_CodePoints_MixedData_1_UTF8(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`       f☃☃       f☃☃        42        42        42        42        42        42        42        42       4.2       4.2       4.2       4.2     false         t`)
 	var v mixedData
 	for i := 0; i < b.N; i++ {
@@ -73,7 +79,65 @@ func BenchmarkDecode_CodePoints_MixedData_1_UTF8(b *testing.B) {
 	}
 }
 
+// validMixedDataLine is a line that decodes into mixedData without error. The line
+// used by the benchmarks above holds 4.2 in the bool field F14, so decoding stops with
+// an error partway through each line.
+const validMixedDataLine = `       foo       foo        42        42        42        42        42        42        42        42       4.2       4.2       4.2      true     false         t`
+
+func BenchmarkUnmarshal_ValidMixedData_1(b *testing.B) {
+	b.ReportAllocs()
+	data := []byte(validMixedDataLine)
+	var v mixedData
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Unmarshal(data, &v)
+	}
+}
+
+func BenchmarkUnmarshal_ValidMixedData_1000(b *testing.B) {
+	b.ReportAllocs()
+	data := bytes.Repeat([]byte(validMixedDataLine+"\n"), 100)
+	var v []mixedData
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Unmarshal(data, &v)
+	}
+}
+
+func BenchmarkUnmarshal_ValidMixedData_100000(b *testing.B) {
+	b.ReportAllocs()
+	data := bytes.Repeat([]byte(validMixedDataLine+"\n"), 10000)
+	var v []mixedData
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = Unmarshal(data, &v)
+	}
+}
+
+func BenchmarkDecode_CodePoints_ValidMixedData_1_Ascii(b *testing.B) {
+	b.ReportAllocs()
+	data := []byte(validMixedDataLine)
+	var v mixedData
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		d.SetUseCodepointIndices(true)
+		_ = d.Decode(&v)
+	}
+}
+
+func BenchmarkDecode_CodePoints_ValidMixedData_1_UTF8(b *testing.B) {
+	b.ReportAllocs()
+	data := []byte(strings.Replace(validMixedDataLine, "foo", "f☃☃", 2))
+	var v mixedData
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		d.SetUseCodepointIndices(true)
+		_ = d.Decode(&v)
+	}
+}
+
 func BenchmarkUnmarshal_String(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`foo       `)
 	var v struct {
 		F1 string `fixed:"1,10"`
@@ -85,6 +149,7 @@ func BenchmarkUnmarshal_String(b *testing.B) {
 }
 
 func BenchmarkUnmarshal_StringPtr(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`foo       `)
 	var v struct {
 		F1 *string `fixed:"1,10"`
@@ -96,6 +161,7 @@ func BenchmarkUnmarshal_StringPtr(b *testing.B) {
 }
 
 func BenchmarkUnmarshal_Int64(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`42       `)
 	var v struct {
 		F1 int64 `fixed:"1,10"`
@@ -107,6 +173,7 @@ func BenchmarkUnmarshal_Int64(b *testing.B) {
 }
 
 func BenchmarkUnmarshal_Float64(b *testing.B) {
+	b.ReportAllocs()
 	data := []byte(`4.2      `)
 	var v struct {
 		F1 float64 `fixed:"1,10"`
@@ -256,3 +323,39 @@ func BenchmarkMarshal_Bool(b *testing.B) {
 		Marshal(v)
 	}
 }
+
+func BenchmarkUnmarshal_MixedData_100000_Concurrency4(b *testing.B) {
+	b.ReportAllocs()
+	data := bytes.Repeat([]byte(`       foo       foo        42        42        42        42        42        42        42        42       4.2       4.2       4.2       4.2     false         t`+"\n"), 10000)
+	var v []mixedData
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		d.SetConcurrency(4)
+		_ = d.Decode(&v)
+	}
+}
+
+func BenchmarkUnmarshal_ValidMixedData_100000_Concurrency4(b *testing.B) {
+	b.ReportAllocs()
+	data := bytes.Repeat([]byte(validMixedDataLine+"\n"), 10000)
+	var v []mixedData
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		d.SetConcurrency(4)
+		_ = d.Decode(&v)
+	}
+}
+
+func BenchmarkUnmarshal_MixedData_100000_Fields2(b *testing.B) {
+	b.ReportAllocs()
+	data := bytes.Repeat([]byte(validMixedDataLine+"\n"), 10000)
+	var v []mixedData
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d := NewDecoder(bytes.NewReader(data))
+		d.SetFields("F1", "F3")
+		_ = d.Decode(&v)
+	}
+}
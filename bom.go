@@ -0,0 +1,56 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrUTF16 is returned by the Decoder when its input starts with a UTF-16 byte order
+// mark. Fixed-width data must be UTF-8 encoded, or in a single byte encoding, so UTF-16
+// input has to be transcoded before it is decoded.
+var ErrUTF16 = errors.New("fixedwidth: input is UTF-16 encoded")
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16BEBOM = []byte{0xfe, 0xff}
+	utf16LEBOM = []byte{0xff, 0xfe}
+)
+
+// SetStripBOM configures whether the Decoder removes a UTF-8 byte order mark from the
+// start of its input, as written by Excel and some .NET tools. Otherwise the mark is
+// part of the first line and shifts each of its fields. A UTF-16 byte order mark gives
+// ErrUTF16.
+//
+// Byte order marks are stripped by default.
+func (d *Decoder) SetStripBOM(strip bool) {
+	d.keepBOM = !strip
+}
+
+// scanBOM is called by the split function until the start of the input has been
+// checked for a byte order mark. It returns the number of bytes to skip, or 0 and
+// false if more data is needed to tell.
+func (d *Decoder) scanBOM(data []byte, atEOF bool) (advance int, ok bool, err error) {
+	if !atEOF {
+		for _, bom := range [][]byte{utf8BOM, utf16BEBOM, utf16LEBOM} {
+			if len(data) < len(bom) && bytes.HasPrefix(bom, data) {
+				return 0, false, nil
+			}
+		}
+	}
+	d.bomChecked = true
+	switch {
+	case bytes.HasPrefix(data, utf8BOM):
+		d.strippedBOM = true
+		return len(utf8BOM), true, nil
+	case bytes.HasPrefix(data, utf16BEBOM), bytes.HasPrefix(data, utf16LEBOM):
+		return 0, false, ErrUTF16
+	}
+	return 0, true, nil
+}
+
+// SetWriteBOM configures whether the Encoder writes a UTF-8 byte order mark before the
+// first line, for consumers such as Excel that expect one. By default no byte order
+// mark is written.
+func (e *Encoder) SetWriteBOM(write bool) {
+	e.writeBOM = write
+}
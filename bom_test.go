@@ -0,0 +1,135 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_BOM(t *testing.T) {
+	type S struct {
+		ID   string `fixed:"1,3"`
+		Name string `fixed:"4,8"`
+	}
+
+	for _, tt := range []struct {
+		name    string
+		in      string
+		setup   func(d *Decoder)
+		want    []S
+		wantErr error
+	}{
+		{
+			name: "UTF-8",
+			in:   "\xef\xbb\xbf001Ann\n002Bob\n",
+			want: []S{{"001", "Ann"}, {"002", "Bob"}},
+		},
+		{
+			name:  "UTF-8 codepoint indices",
+			in:    "\xef\xbb\xbf001Ané\n002Bob\n",
+			setup: func(d *Decoder) { d.SetUseCodepointIndices(true) },
+			want:  []S{{"001", "Ané"}, {"002", "Bob"}},
+		},
+		{
+			name:  "UTF-8 record length",
+			in:    "\xef\xbb\xbf001Ann  002Bob  ",
+			setup: func(d *Decoder) { d.SetRecordLength(8) },
+			want:  []S{{"001", "Ann"}, {"002", "Bob"}},
+		},
+		{
+			name:  "not stripped",
+			in:    "\xef\xbb\xbf001Ann\n",
+			setup: func(d *Decoder) { d.SetStripBOM(false) },
+			want:  []S{{"\xef\xbb\xbf", "001An"}},
+		},
+		{
+			name: "only BOM",
+			in:   "\xef\xbb\xbf",
+		},
+		{
+			name: "partial BOM",
+			in:   "\xef\xbb",
+			want: []S{{"\xef\xbb", ""}},
+		},
+		{
+			name: "no BOM",
+			in:   "001Ann\n",
+			want: []S{{"001", "Ann"}},
+		},
+		{
+			name: "BOM after the first line",
+			in:   "001Ann\n\xef\xbb\xbf\n",
+			want: []S{{"001", "Ann"}, {"\xef\xbb\xbf", ""}},
+		},
+		{
+			name:    "UTF-16LE",
+			in:      "\xff\xfe0\x000\x001\x00",
+			wantErr: ErrUTF16,
+		},
+		{
+			name:    "UTF-16BE",
+			in:      "\xfe\xff\x000\x000\x001",
+			wantErr: ErrUTF16,
+		},
+	} {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.in)
+			name := tt.name
+			if oneByte {
+				r = iotest.OneByteReader(r)
+				name += " one byte at a time"
+			}
+			t.Run(name, func(t *testing.T) {
+				d := NewDecoder(r)
+				if tt.setup != nil {
+					tt.setup(d)
+				}
+				var have []S
+				err := d.Decode(&have)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Decode() want error %v, have %v", tt.wantErr, err)
+				}
+				if !reflect.DeepEqual(have, tt.want) {
+					t.Errorf("Decode() want %q, have %q", tt.want, have)
+				}
+			})
+		}
+	}
+}
+
+func TestEncoder_SetWriteBOM(t *testing.T) {
+	type S struct {
+		ID   string `fixed:"1,3"`
+		Name string `fixed:"4,8"`
+	}
+
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	e.SetWriteBOM(true)
+	if err := e.Encode([]S(nil)); err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("Encode() of no values want no output, have %q", buf.String())
+	}
+	for _, v := range [][]S{{{"001", "Ann"}, {"002", "Bob"}}, {{"003", "Cy"}}} {
+		if err := e.Encode(v); err != nil {
+			t.Fatalf("Encode() unexpected error: %v", err)
+		}
+	}
+	if want := "\xef\xbb\xbf001Ann  \n002Bob  003Cy   "; buf.String() != want {
+		t.Errorf("Encode() want %q, have %q", want, buf.String())
+	}
+
+	var have []S
+	if err := Unmarshal(buf.Bytes(), &have); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if want := []S{{"001", "Ann"}, {"002", "Bob"}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("Unmarshal() want %q, have %q", want, have)
+	}
+}
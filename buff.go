@@ -3,7 +3,6 @@ package fixedwidth
 import (
 	"bytes"
 	"errors"
-	"strings"
 	"unicode/utf8"
 )
 
@@ -176,53 +175,80 @@ func (b *lineBuilder) hasMultiByteChar() bool {
 	return b.codepointIndices != nil
 }
 
+// rawValue is an encoded value, or a whole line, produced by the Encoder.
 type rawValue struct {
 	data string
-	// Used when `SetUseCodepointIndices` has been called on `Decoder` or `Encoder`. A
+	// Used when `SetUseCodepointIndices` has been called on `Encoder`. A
 	// mapping of codepoint indices into the bytes. So the `codepointIndices[n]` is the
 	// starting position for the n-th codepoint in `bytes`.
 	codepointIndices []int
 }
 
-func (r rawValue) trimLeft(cutset string) rawValue {
-	newData := strings.TrimLeft(r.data, cutset)
+// rawBytes is a view of a line, or of a field within it, that is being decoded. data
+// usually aliases the buffer of the Decoder's scanner, so it must be copied before it
+// is retained.
+type rawBytes struct {
+	data []byte
+	// Used when `SetUseCodepointIndices` has been called on `Decoder`. See
+	// rawValue.codepointIndices.
+	codepointIndices []int
+}
+
+// newRawBytes returns a rawBytes that views data. Codepoint indices are only computed
+// if useCodepointIndices is set and data contains multi-byte characters.
+func newRawBytes(data []byte, useCodepointIndices bool) rawBytes {
+	value := rawBytes{data: data}
+	if useCodepointIndices {
+		if n := utf8.RuneCount(data); n < len(data) {
+			codepointIndices := make([]int, 0, n)
+			for i := range string(data) {
+				codepointIndices = append(codepointIndices, i)
+			}
+			value.codepointIndices = codepointIndices
+		}
+	}
+	return value
+}
+
+func (r rawBytes) trimLeft(cutset string) rawBytes {
+	newData := bytes.TrimLeft(r.data, cutset)
 	leftRemovedBytes := len(r.data) - len(newData)
 
 	if r.codepointIndices == nil {
-		return rawValue{data: newData}
+		return rawBytes{data: newData}
 	}
 
 	newIndices := r.trimCodepointIndices(leftRemovedBytes, 0)
-	return rawValue{data: newData, codepointIndices: newIndices}
+	return rawBytes{data: newData, codepointIndices: newIndices}
 }
 
-func (r rawValue) trimRight(cutset string) rawValue {
-	newData := strings.TrimRight(r.data, cutset)
+func (r rawBytes) trimRight(cutset string) rawBytes {
+	newData := bytes.TrimRight(r.data, cutset)
 	rightRemovedBytes := len(r.data) - len(newData)
 
 	if r.codepointIndices == nil {
-		return rawValue{data: newData}
+		return rawBytes{data: newData}
 	}
 
 	newIndices := r.trimCodepointIndices(0, rightRemovedBytes)
-	return rawValue{data: newData, codepointIndices: newIndices}
+	return rawBytes{data: newData, codepointIndices: newIndices}
 }
 
-func (r rawValue) trim(cutset string) rawValue {
-	leftTrimmed := strings.TrimLeft(r.data, cutset)
+func (r rawBytes) trim(cutset string) rawBytes {
+	leftTrimmed := bytes.TrimLeft(r.data, cutset)
 	leftRemovedBytes := len(r.data) - len(leftTrimmed)
-	bothTrimmed := strings.TrimRight(leftTrimmed, cutset)
+	bothTrimmed := bytes.TrimRight(leftTrimmed, cutset)
 	rightRemovedBytes := len(leftTrimmed) - len(bothTrimmed)
 
 	if r.codepointIndices == nil {
-		return rawValue{data: bothTrimmed}
+		return rawBytes{data: bothTrimmed}
 	}
 
 	newIndices := r.trimCodepointIndices(leftRemovedBytes, rightRemovedBytes)
-	return rawValue{data: bothTrimmed, codepointIndices: newIndices}
+	return rawBytes{data: bothTrimmed, codepointIndices: newIndices}
 }
 
-func (r rawValue) trimCodepointIndices(leftRemovedBytes int, rightRemovedBytes int) []int {
+func (r rawBytes) trimCodepointIndices(leftRemovedBytes int, rightRemovedBytes int) []int {
 	newIndices := make([]int, 0, len(r.codepointIndices))
 	for _, idx := range r.codepointIndices {
 		if idx >= leftRemovedBytes && idx < len(r.data)-rightRemovedBytes {
@@ -0,0 +1,47 @@
+package fixedwidth
+
+import "io"
+
+// A Checkpoint records the position of a Decoder in its input, so that decoding can be
+// resumed by NewDecoderAt, for example after a long-running job was interrupted. A
+// Checkpoint can be stored as JSON.
+type Checkpoint struct {
+	Line       int    // number of lines read, as reported by Decoder.LineNumber
+	Offset     int64  // offset of the next line, as reported by Decoder.InputOffset
+	Terminator []byte // terminator of the last line, as reported by Decoder.Terminator
+}
+
+// Checkpoint returns the position of the Decoder after the line that was read last.
+// Take a checkpoint once the values decoded so far have been processed; decoding
+// resumes with the line after it.
+//
+// While Reader.Each decodes lines concurrently, Checkpoint may be called from its
+// callback and returns the position after the value passed to it.
+func (d *Decoder) Checkpoint() Checkpoint {
+	return Checkpoint{
+		Line:       d.LineNumber(),
+		Offset:     d.InputOffset(),
+		Terminator: append([]byte(nil), d.Terminator()...),
+	}
+}
+
+// NewDecoderAt returns a new decoder that resumes decoding r at the checkpoint cp. r is
+// the input the checkpoint was taken from, such as a reopened file, and is seeked to
+// the offset of the checkpoint. Line numbers continue from the checkpoint, so errors
+// report the same lines as for the original Decoder.
+//
+// The Decoder must be configured in the same way as the one the checkpoint was taken
+// from. Header lines that were skipped before the checkpoint are not skipped again, and
+// a byte order mark is only stripped when resuming at the start of the input.
+func NewDecoderAt(r io.ReadSeeker, cp Checkpoint) (*Decoder, error) {
+	if _, err := r.Seek(cp.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	dec := NewDecoder(r)
+	dec.lineNum = cp.Line
+	dec.offset = cp.Offset
+	dec.scanOffset = cp.Offset
+	dec.terminator = cp.Terminator
+	dec.bomChecked = cp.Offset > 0
+	return dec, nil
+}
@@ -0,0 +1,140 @@
+package fixedwidth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_Checkpoint(t *testing.T) {
+	type S struct {
+		ID int `fixed:"1,3"`
+	}
+
+	const in = "\xef\xbb\xbfHDR\r\n001\r\n002\n003\r\nxxx\r\n005\r\nTRL\r\n"
+	setup := func(d *Decoder) {
+		d.SetUniversalNewlines(true)
+		d.SetSkipHeader(1)
+		d.SetSkipTrailer(1)
+	}
+
+	d := NewDecoder(strings.NewReader(in))
+	setup(d)
+	var first []S
+	for i := 0; i < 2; i++ {
+		var s S
+		if err := d.Decode(&s); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		first = append(first, s)
+	}
+	if want := []S{{1}, {2}}; !reflect.DeepEqual(first, want) {
+		t.Fatalf("Decode() want %v, have %v", want, first)
+	}
+
+	// Store the checkpoint as a job would.
+	b, err := json.Marshal(d.Checkpoint())
+	if err != nil {
+		t.Fatalf("json.Marshal() unexpected error: %v", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		t.Fatalf("json.Unmarshal() unexpected error: %v", err)
+	}
+	if want := (Checkpoint{Line: 3, Offset: 17, Terminator: []byte("\n")}); !reflect.DeepEqual(cp, want) {
+		t.Fatalf("Checkpoint() want %+v, have %+v", want, cp)
+	}
+
+	d, err = NewDecoderAt(strings.NewReader(in), cp)
+	if err != nil {
+		t.Fatalf("NewDecoderAt() unexpected error: %v", err)
+	}
+	setup(d)
+	if d.LineNumber() != 3 || d.InputOffset() != 17 || string(d.Terminator()) != "\n" {
+		t.Errorf("NewDecoderAt() want position 3, 17, %q, have %d, %d, %q", "\n", d.LineNumber(), d.InputOffset(), d.Terminator())
+	}
+
+	var s S
+	if err := d.Decode(&s); err != nil || s.ID != 3 || d.LineNumber() != 4 {
+		t.Fatalf("Decode() after resuming want 3 on line 4, have %v on line %d, %v", s.ID, d.LineNumber(), err)
+	}
+	var typeErr *UnmarshalTypeError
+	if err := d.Decode(&s); !errors.As(err, &typeErr) || typeErr.Line != 5 {
+		t.Fatalf("Decode() want error on line 5, have %v", err)
+	}
+	if err := d.Decode(&s); err != nil || s.ID != 5 {
+		t.Fatalf("Decode() want 5, have %v, %v", s.ID, err)
+	}
+	if err := d.Decode(&s); err != io.EOF {
+		t.Fatalf("Decode() want io.EOF, have %v", err)
+	}
+
+	t.Run("start of input", func(t *testing.T) {
+		d, err := NewDecoderAt(strings.NewReader(in), Checkpoint{})
+		if err != nil {
+			t.Fatalf("NewDecoderAt() unexpected error: %v", err)
+		}
+		setup(d)
+		var s S
+		if err := d.Decode(&s); err != nil || s.ID != 1 || d.LineNumber() != 2 {
+			t.Fatalf("Decode() want 1 on line 2, have %v on line %d, %v", s.ID, d.LineNumber(), err)
+		}
+	})
+	t.Run("concurrent each", func(t *testing.T) {
+		var b strings.Builder
+		for i := 0; i < 1000; i++ {
+			b.WriteString(fmt.Sprintf("%03d", i%1000))
+			b.WriteString([]string{"\n", "\r\n"}[i%2])
+		}
+		in := b.String()
+
+		checkpoints := func(concurrency int) []Checkpoint {
+			d := NewDecoder(strings.NewReader(in))
+			d.SetUniversalNewlines(true)
+			d.SetConcurrency(concurrency)
+			var cps []Checkpoint
+			err := NewReader[S](d).Each(func(s S) error {
+				cps = append(cps, d.Checkpoint())
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Each() unexpected error: %v", err)
+			}
+			return cps
+		}
+		want := checkpoints(0)
+		if have := checkpoints(4); !reflect.DeepEqual(have, want) {
+			t.Fatalf("Each() with concurrency took %d checkpoints that differ from the %d without", len(have), len(want))
+		}
+
+		// Stop partway through and resume from the checkpoint of the last value.
+		d := NewDecoder(strings.NewReader(in))
+		d.SetUniversalNewlines(true)
+		d.SetConcurrency(4)
+		stop := errors.New("stop")
+		var cp Checkpoint
+		err := NewReader[S](d).Each(func(s S) error {
+			cp = d.Checkpoint()
+			if s.ID == 600 {
+				return stop
+			}
+			return nil
+		})
+		if err != stop {
+			t.Fatalf("Each() want stop, have %v", err)
+		}
+		d, err = NewDecoderAt(strings.NewReader(in), cp)
+		if err != nil {
+			t.Fatalf("NewDecoderAt() unexpected error: %v", err)
+		}
+		d.SetUniversalNewlines(true)
+		var s S
+		if err := d.Decode(&s); err != nil || s.ID != 601 || d.LineNumber() != 602 {
+			t.Fatalf("Decode() after resuming want 601 on line 602, have %v on line %d, %v", s.ID, d.LineNumber(), err)
+		}
+	})
+}
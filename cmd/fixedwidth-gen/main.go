@@ -0,0 +1,529 @@
+// Command fixedwidth-gen generates reflection-free fixed-width encoders and decoders.
+//
+// For every struct type with `fixed` tags, fixedwidth-gen writes a MarshalFixedWidth
+// method implementing fixedwidth.Marshaler and an UnmarshalFixedWidth method
+// implementing fixedwidth.Unmarshaler. The Encoder and Decoder prefer these methods
+// to reflection unless codepoint indices are in use.
+//
+// It is intended to be run by go generate:
+//
+//	//go:generate go run github.com/ianlopshire/go-fixedwidth/cmd/fixedwidth-gen -type Person
+//
+// Usage:
+//
+//	fixedwidth-gen [flags] [dir]
+//
+// The flags are:
+//
+//	-type names
+//		comma-separated list of type names; defaults to every struct type with
+//		`fixed` tags declared in $GOFILE, or in the package if $GOFILE is unset
+//	-output file
+//		output file name; defaults to <gofile>_fixedwidth.go, or
+//		fixedwidth_gen.go if $GOFILE is unset
+//
+// Fields may be strings, booleans, integers, floating-point numbers, pointers to any
+// of those, or types implementing encoding.TextMarshaler and
+// encoding.TextUnmarshaler. Structs with fields of other types are rejected.
+//
+// An untagged fixedwidth.RawLine field keeps the decoded line and is used as the
+// starting point when encoding, in the same way as with reflection.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of type `names`")
+		output    = flag.String("output", "", "output `file` name")
+	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: fixedwidth-gen [flags] [dir]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	gofile := os.Getenv("GOFILE")
+	out := *output
+	switch {
+	case out != "":
+	case gofile != "":
+		out = strings.TrimSuffix(gofile, ".go") + "_fixedwidth.go"
+	default:
+		out = "fixedwidth_gen.go"
+	}
+	if !filepath.IsAbs(out) {
+		out = filepath.Join(dir, out)
+	}
+
+	src, err := generate(dir, gofile, names, filepath.Base(out))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "fixedwidth-gen:", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, "fixedwidth-gen:", err)
+		os.Exit(1)
+	}
+}
+
+// generate returns the source of a file implementing fixedwidth.Marshaler and
+// fixedwidth.Unmarshaler for struct types of the package in dir.
+//
+// If names is empty, every struct type with `fixed` tags is used. If gofile is not
+// empty, only the types declared in that file are considered. The file named output
+// is ignored when loading the package so that stale generated code does not prevent
+// it from being type checked.
+func generate(dir, gofile string, names []string, output string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go") && fi.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, errors.New("expected exactly one package in " + dir)
+	}
+
+	var (
+		pkgName string
+		files   []*ast.File
+	)
+	for name, pkg := range pkgs {
+		pkgName = name
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return fset.Position(files[i].Pos()).Filename < fset.Position(files[j].Pos()).Filename
+	})
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check(pkgName, fset, files, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{pkg: pkg, imports: map[string]string{}}
+	if err := g.loadTextInterfaces(conf.Importer); err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		for _, f := range files {
+			if gofile != "" && filepath.Base(fset.Position(f.Pos()).Filename) != gofile {
+				continue
+			}
+			for _, decl := range f.Decls {
+				gd, ok := decl.(*ast.GenDecl)
+				if !ok || gd.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gd.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if hasFixedTags(pkg, ts.Name.Name) {
+						names = append(names, ts.Name.Name)
+					}
+				}
+			}
+		}
+		if len(names) == 0 {
+			return nil, errors.New("no struct types with fixed tags found")
+		}
+	}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			return nil, errors.New("type " + name + " not found")
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok {
+			return nil, errors.New(name + " is not a named type")
+		}
+		st, ok := named.Underlying().(*types.Struct)
+		if !ok {
+			return nil, errors.New(name + " is not a struct type")
+		}
+		if err := g.writeType(&body, name, st); err != nil {
+			return nil, err
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by fixedwidth-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	b.WriteString("import (\n")
+	var paths []string
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&b, "\t%q\n", path)
+	}
+	if len(paths) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("\t\"github.com/ianlopshire/go-fixedwidth/fwgen\"\n)\n")
+	b.Write(body.Bytes())
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, errors.New("formatting generated code: " + err.Error())
+	}
+	return src, nil
+}
+
+// hasFixedTags reports whether the named type is a struct with at least one valid
+// `fixed` tag.
+func hasFixedTags(pkg *types.Package, name string) bool {
+	st, ok := pkg.Scope().Lookup(name).Type().Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		if _, ok := parseTag(reflect.StructTag(st.Tag(i)).Get("fixed")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type generator struct {
+	pkg *types.Package
+
+	// imports records the packages referenced by the generated code.
+	imports map[string]string
+
+	textMarshaler   *types.Interface
+	textUnmarshaler *types.Interface
+}
+
+func (g *generator) loadTextInterfaces(imp types.Importer) error {
+	encoding, err := imp.Import("encoding")
+	if err != nil {
+		return err
+	}
+	g.textMarshaler = encoding.Scope().Lookup("TextMarshaler").Type().Underlying().(*types.Interface)
+	g.textUnmarshaler = encoding.Scope().Lookup("TextUnmarshaler").Type().Underlying().(*types.Interface)
+	return nil
+}
+
+// typeString returns the Go syntax for t as seen from the generated file.
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// A field is a struct field with a valid `fixed` tag.
+type field struct {
+	name string
+	typ  types.Type
+	tag  tag
+
+	// pointer is true if the field is a pointer to a value of type elem.
+	pointer bool
+	elem    types.Type
+}
+
+func (g *generator) writeType(b *bytes.Buffer, name string, st *types.Struct) error {
+	var (
+		fields  []field
+		ll      int
+		rawLine string
+	)
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		if isRawLine(v.Type()) && v.Exported() && reflect.StructTag(st.Tag(i)).Get("fixed") == "" {
+			if rawLine == "" {
+				rawLine = v.Name()
+			}
+			continue
+		}
+		tag, ok := parseTag(reflect.StructTag(st.Tag(i)).Get("fixed"))
+		if !ok {
+			continue
+		}
+		f := field{name: v.Name(), typ: v.Type(), tag: tag, elem: v.Type()}
+		if p, ok := v.Type().(*types.Pointer); ok && !g.isText(v.Type()) {
+			f.pointer = true
+			f.elem = p.Elem()
+		}
+		if kind := g.kind(f.elem); kind == "" {
+			return errors.New(name + "." + f.name + ": unsupported type " + g.typeString(v.Type()))
+		}
+		fields = append(fields, f)
+		if tag.end > ll {
+			ll = tag.end
+		}
+	}
+	if len(fields) == 0 {
+		return errors.New(name + " has no fields with fixed tags")
+	}
+
+	fmt.Fprintf(b, "\n// MarshalFixedWidth implements fixedwidth.Marshaler.\n")
+	fmt.Fprintf(b, "func (v %s) MarshalFixedWidth() ([]byte, error) {\n", name)
+	if rawLine != "" {
+		fmt.Fprintf(b, "line := fwgen.NewLineFrom(v.%s, %d)\n", rawLine, ll)
+		for _, f := range fields {
+			fmt.Fprintf(b, "fwgen.Blank(line, %d, %d)\n", f.tag.start, f.tag.end)
+		}
+	} else {
+		fmt.Fprintf(b, "line := fwgen.NewLine(%d)\n", ll)
+	}
+	if needsScratch(g, fields) {
+		b.WriteString("var buf [64]byte\n")
+	}
+	for _, f := range fields {
+		g.writeEncodeField(b, f)
+	}
+	b.WriteString("return line, nil\n}\n")
+
+	fmt.Fprintf(b, "\n// UnmarshalFixedWidth implements fixedwidth.Unmarshaler.\n")
+	fmt.Fprintf(b, "func (v *%s) UnmarshalFixedWidth(data []byte) error {\n", name)
+	b.WriteString("var b []byte\n")
+	for _, f := range fields {
+		g.writeDecodeField(b, name, f)
+	}
+	if rawLine != "" {
+		fmt.Fprintf(b, "v.%[1]s = append(v.%[1]s[:0:0], data...)\n", rawLine)
+	}
+	b.WriteString("return nil\n}\n")
+	return nil
+}
+
+// isRawLine reports whether t is fixedwidth.RawLine.
+func isRawLine(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Name() == "RawLine" && obj.Pkg() != nil && obj.Pkg().Path() == "github.com/ianlopshire/go-fixedwidth"
+}
+
+func needsScratch(g *generator, fields []field) bool {
+	for _, f := range fields {
+		switch g.kind(f.elem) {
+		case "int", "uint", "float32", "float64", "bool":
+			return true
+		}
+	}
+	return false
+}
+
+// isText reports whether t implements both encoding.TextMarshaler and, through a
+// pointer, encoding.TextUnmarshaler.
+func (g *generator) isText(t types.Type) bool {
+	ptr := t
+	if _, ok := t.(*types.Pointer); !ok {
+		ptr = types.NewPointer(t)
+	}
+	return types.Implements(t, g.textMarshaler) && types.Implements(ptr, g.textUnmarshaler)
+}
+
+// kind returns the way values of type t are encoded, or "" if t is not supported.
+func (g *generator) kind(t types.Type) string {
+	if g.isText(t) {
+		return "text"
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	switch basic.Kind() {
+	case types.String:
+		return "string"
+	case types.Bool:
+		return "bool"
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		return "int"
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return "uint"
+	case types.Float32:
+		return "float32"
+	case types.Float64:
+		return "float64"
+	}
+	return ""
+}
+
+func (g *generator) writeEncodeField(b *bytes.Buffer, f field) {
+	args := fmt.Sprintf("line, %d, %d, %s, %s", f.tag.start, f.tag.end, f.tag.alignment, strconv.QuoteRune(rune(f.tag.pad)))
+
+	value := "v." + f.name
+	if f.pointer {
+		fmt.Fprintf(b, "if v.%s == nil {\nfwgen.PutString(%s, \"\")\n} else {\n", f.name, args)
+		value = "*v." + f.name
+	}
+
+	switch g.kind(f.elem) {
+	case "text":
+		if _, ok := f.typ.(*types.Pointer); ok {
+			fmt.Fprintf(b, "if v.%s == nil {\nfwgen.PutString(%s, \"\")\n} else ", f.name, args)
+		}
+		fmt.Fprintf(b, "if text, err := %s.MarshalText(); err != nil {\nreturn nil, err\n} else {\nfwgen.Put(%s, text)\n}\n", "v."+f.name, args)
+	case "string":
+		fmt.Fprintf(b, "fwgen.PutString(%s, string(%s))\n", args, value)
+	case "bool":
+		fmt.Fprintf(b, "fwgen.Put(%s, strconv.AppendBool(buf[:0], bool(%s)))\n", args, value)
+		g.imports["strconv"] = "strconv"
+	case "int":
+		fmt.Fprintf(b, "fwgen.Put(%s, strconv.AppendInt(buf[:0], int64(%s), 10))\n", args, value)
+		g.imports["strconv"] = "strconv"
+	case "uint":
+		fmt.Fprintf(b, "fwgen.Put(%s, strconv.AppendUint(buf[:0], uint64(%s), 10))\n", args, value)
+		g.imports["strconv"] = "strconv"
+	case "float32":
+		fmt.Fprintf(b, "fwgen.Put(%s, strconv.AppendFloat(buf[:0], float64(%s), 'f', 2, 32))\n", args, value)
+		g.imports["strconv"] = "strconv"
+	case "float64":
+		fmt.Fprintf(b, "fwgen.Put(%s, strconv.AppendFloat(buf[:0], float64(%s), 'f', 2, 64))\n", args, value)
+		g.imports["strconv"] = "strconv"
+	}
+
+	if f.pointer {
+		b.WriteString("}\n")
+	}
+}
+
+func (g *generator) writeDecodeField(b *bytes.Buffer, structName string, f field) {
+	fmt.Fprintf(b, "b = fwgen.Field(data, %d, %d, %s, %s)\n", f.tag.start, f.tag.end, f.tag.alignment, strconv.QuoteRune(rune(f.tag.pad)))
+
+	typeErr := fmt.Sprintf("return fwgen.TypeError(data, &v.%s, %q, %q, err)", f.name, structName, f.name)
+
+	if g.kind(f.elem) == "text" {
+		if _, ok := f.typ.(*types.Pointer); ok {
+			fmt.Fprintf(b, "if v.%s == nil {\nv.%s = new(%s)\n}\n", f.name, f.name, g.typeString(f.typ.(*types.Pointer).Elem()))
+		}
+		fmt.Fprintf(b, "if err := v.%s.UnmarshalText(b); err != nil {\n%s\n}\n", f.name, typeErr)
+		return
+	}
+
+	elem := g.typeString(f.elem)
+	target := "v." + f.name
+	if f.pointer {
+		fmt.Fprintf(b, "if len(b) == 0 {\nv.%s = nil\n} else {\nif v.%s == nil {\nv.%s = new(%s)\n}\n", f.name, f.name, f.name, elem)
+		target = "*v." + f.name
+	}
+
+	switch kind := g.kind(f.elem); kind {
+	case "string":
+		fmt.Fprintf(b, "%s = %s(b)\n", target, elem)
+	default:
+		var parse string
+		switch kind {
+		case "bool":
+			parse = "fwgen.ParseBool(b)"
+		case "int":
+			parse = "fwgen.ParseInt(b)"
+		case "uint":
+			parse = "fwgen.ParseUint(b)"
+		case "float32":
+			parse = "fwgen.ParseFloat(b, 32)"
+		case "float64":
+			parse = "fwgen.ParseFloat(b, 64)"
+		}
+		if !f.pointer {
+			b.WriteString("if len(b) > 0 {\n")
+		}
+		fmt.Fprintf(b, "x, err := %s\nif err != nil {\n%s\n}\n%s = %s(x)\n", parse, typeErr, target, elem)
+		if !f.pointer {
+			b.WriteString("}\n")
+		}
+	}
+
+	if f.pointer {
+		b.WriteString("}\n")
+	}
+}
+
+// A tag is a parsed `fixed` struct tag.
+type tag struct {
+	start, end int
+	// alignment is the name of the fwgen alignment constant.
+	alignment string
+	pad       byte
+}
+
+// parseTag parses a `fixed` struct tag in the same way as package fixedwidth.
+func parseTag(s string) (tag, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 || len(parts) > 4 {
+		return tag{}, false
+	}
+
+	var (
+		t   = tag{alignment: "fwgen.AlignDefault", pad: ' '}
+		err error
+	)
+	if t.start, err = strconv.Atoi(parts[0]); err != nil {
+		return tag{}, false
+	}
+	if t.end, err = strconv.Atoi(parts[1]); err != nil {
+		return tag{}, false
+	}
+	if t.start > t.end || (t.start == 0 && t.end == 0) {
+		return tag{}, false
+	}
+
+	if len(parts) >= 3 {
+		switch parts[2] {
+		case "left":
+			t.alignment = "fwgen.AlignLeft"
+		case "right":
+			t.alignment = "fwgen.AlignRight"
+		case "none":
+			t.alignment = "fwgen.AlignNone"
+		}
+	}
+
+	if len(parts) >= 4 {
+		v := parts[3]
+		switch {
+		case v == "_":
+			t.pad = ' '
+		case v == "__":
+			t.pad = '_'
+		case len(v) > 0:
+			t.pad = v[0]
+		}
+	}
+	return t, true
+}
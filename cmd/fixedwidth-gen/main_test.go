@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_UpToDate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "gentest")
+	got, err := generate(dir, "types.go", nil, "types_fixedwidth.go")
+	if err != nil {
+		t.Fatalf("generate() unexpected error: %v", err)
+	}
+	want, err := os.ReadFile(filepath.Join(dir, "types_fixedwidth.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("internal/gentest/types_fixedwidth.go is out of date; run go generate ./internal/gentest")
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		src   string
+		names []string
+		want  string
+	}{
+		{
+			name: "unsupported field",
+			src:  "package p\ntype T struct {\n\tA []string `fixed:\"1,5\"`\n}\n",
+			want: "T.A: unsupported type []string",
+		},
+		{
+			name: "no tags",
+			src:  "package p\ntype T struct {\n\tA string\n}\n",
+			want: "no struct types with fixed tags found",
+		},
+		{
+			name:  "not a struct",
+			src:   "package p\ntype T int\n",
+			names: []string{"T"},
+			want:  "T is not a struct type",
+		},
+		{
+			name:  "unknown type",
+			src:   "package p\n",
+			names: []string{"T"},
+			want:  "type T not found",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(tt.src), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := generate(dir, "", tt.names, "fixedwidth_gen.go")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("generate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+func runConvert(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: fixedwidth convert [flags] [file]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Convert records between fixed-width, CSV, TSV, and JSON Lines. Input is read")
+		fmt.Fprintln(fs.Output(), "from file, or from stdin if no file is given.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var (
+		lf      layoutFlags
+		cf      codecFlags
+		from    = fs.String("from", "fixed", "input `format`: fixed, csv, tsv, or jsonl")
+		to      = fs.String("to", "csv", "output `format`: fixed, csv, tsv, or jsonl")
+		header  = fs.Bool("header", true, "csv and tsv data starts with a header row")
+		onError = fs.String("on-error", "fail", "what to do with a record that cannot be converted: fail, skip, or warn")
+		output  = fs.String("o", "", "write output to `file` instead of stdout")
+	)
+	lf.register(fs)
+	cf.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	switch *onError {
+	case "fail", "skip", "warn":
+	default:
+		return errors.New("invalid -on-error value " + strconv.Quote(*onError))
+	}
+
+	layout, err := lf.load()
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	r, err := newRecordReader(*from, in, layout, cf, *header)
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(out)
+
+	w, err := newRecordWriter(*to, bw, layout, cf, *header)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	err = convert(r, w, layout, *onError, stderr)
+	if ferr := w.Flush(); err == nil {
+		err = ferr
+	}
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// convert copies every record from r to w. Records that cannot be converted are
+// handled according to onError.
+func convert(r recordReader, w recordWriter, layout *fixedwidth.Layout, onError string, stderr io.Writer) error {
+	for {
+		values, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err == nil {
+			err = checkTypes(layout, values)
+			if err != nil {
+				err = &recordError{line: r.Line(), err: err}
+			}
+		}
+		if err == nil {
+			err = w.Write(values)
+		}
+
+		var rerr *recordError
+		switch {
+		case err == nil:
+			continue
+		case !errors.As(err, &rerr) || onError == "fail":
+			return err
+		case onError == "warn":
+			fmt.Fprintln(stderr, "fixedwidth convert: skipping", err)
+		}
+	}
+}
+
+// A recordError describes a single record that could not be converted. Unlike other
+// errors, conversion can continue after a recordError.
+type recordError struct {
+	line int
+	err  error
+}
+
+func (e *recordError) Error() string {
+	return "record " + strconv.Itoa(e.line) + ": " + e.err.Error()
+}
+
+func (e *recordError) Unwrap() error {
+	return e.err
+}
+
+// checkTypes reports whether every non-empty value can be parsed as the type of its
+// layout field.
+func checkTypes(layout *fixedwidth.Layout, values []string) error {
+	for i, f := range layout.Fields {
+		if values[i] == "" {
+			continue
+		}
+		var err error
+		switch f.Type {
+		case fixedwidth.TypeInt:
+			_, err = strconv.ParseInt(values[i], 10, 64)
+		case fixedwidth.TypeDecimal:
+			_, err = strconv.ParseFloat(values[i], 64)
+		}
+		if err != nil {
+			return errors.New("field " + f.Name + ": invalid " + f.Type + " " + strconv.Quote(values[i]))
+		}
+	}
+	return nil
+}
+
+// A recordReader reads records in some format. Values are returned in the order of
+// the fields of the layout.
+type recordReader interface {
+	Read() ([]string, error)
+
+	// Line returns the number of the record most recently read.
+	Line() int
+}
+
+// A recordWriter writes records in some format.
+type recordWriter interface {
+	Write(values []string) error
+	Flush() error
+}
+
+func newRecordReader(format string, r io.Reader, layout *fixedwidth.Layout, cf codecFlags, header bool) (recordReader, error) {
+	switch format {
+	case "fixed":
+		dec, err := cf.newDecoder(r)
+		if err != nil {
+			return nil, err
+		}
+		return &fixedReader{dec: dec, rec: layout.NewRecord()}, nil
+	case "csv", "tsv":
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		if format == "tsv" {
+			cr.Comma = '\t'
+			cr.LazyQuotes = true
+		}
+		return &csvReader{r: cr, layout: layout, header: header}, nil
+	case "jsonl":
+		return &jsonReader{s: bufio.NewScanner(r), layout: layout}, nil
+	}
+	return nil, errors.New("unknown input format " + strconv.Quote(format))
+}
+
+func newRecordWriter(format string, w io.Writer, layout *fixedwidth.Layout, cf codecFlags, header bool) (recordWriter, error) {
+	switch format {
+	case "fixed":
+		enc, err := cf.newEncoder(w)
+		if err != nil {
+			return nil, err
+		}
+		term, _ := cf.lineTerminator()
+		return &fixedWriter{w: w, enc: enc, rec: layout.NewRecord(), terminator: term}, nil
+	case "csv", "tsv":
+		cw := csv.NewWriter(w)
+		if format == "tsv" {
+			cw.Comma = '\t'
+		}
+		return &csvWriter{w: cw, layout: layout, header: header}, nil
+	case "jsonl":
+		return &jsonWriter{w: w, layout: layout}, nil
+	}
+	return nil, errors.New("unknown output format " + strconv.Quote(format))
+}
+
+type fixedReader struct {
+	dec  *fixedwidth.Decoder
+	rec  *fixedwidth.Record
+	line int
+}
+
+func (r *fixedReader) Read() ([]string, error) {
+	if err := r.dec.Decode(r.rec); err != nil {
+		return nil, err
+	}
+	r.line++
+	return r.rec.Values, nil
+}
+
+func (r *fixedReader) Line() int { return r.line }
+
+type fixedWriter struct {
+	w          io.Writer
+	enc        *fixedwidth.Encoder
+	rec        *fixedwidth.Record
+	terminator []byte
+}
+
+func (w *fixedWriter) Write(values []string) error {
+	w.rec.Values = values
+	if err := w.enc.Encode(w.rec); err != nil {
+		return err
+	}
+	_, err := w.w.Write(w.terminator)
+	return err
+}
+
+func (w *fixedWriter) Flush() error { return nil }
+
+type csvReader struct {
+	r      *csv.Reader
+	layout *fixedwidth.Layout
+	header bool
+
+	// columns maps each column of the input to the index of a layout field, or -1
+	// if the column is not part of the layout.
+	columns []int
+	line    int
+}
+
+func (r *csvReader) Read() ([]string, error) {
+	if r.header && r.columns == nil {
+		names, err := r.r.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.columns = make([]int, len(names))
+		for i, name := range names {
+			r.columns[i] = r.layout.Index(name)
+		}
+	}
+
+	record, err := r.r.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	r.line++
+	if err != nil {
+		var perr *csv.ParseError
+		if errors.As(err, &perr) {
+			return nil, &recordError{line: r.line, err: err}
+		}
+		return nil, err
+	}
+
+	values := make([]string, len(r.layout.Fields))
+	if r.columns == nil {
+		if len(record) != len(values) {
+			return nil, &recordError{line: r.line, err: fmt.Errorf("have %d columns, want %d", len(record), len(values))}
+		}
+		copy(values, record)
+		return values, nil
+	}
+
+	if len(record) != len(r.columns) {
+		return nil, &recordError{line: r.line, err: fmt.Errorf("have %d columns, want %d", len(record), len(r.columns))}
+	}
+	for i, v := range record {
+		if j := r.columns[i]; j >= 0 {
+			values[j] = v
+		}
+	}
+	return values, nil
+}
+
+func (r *csvReader) Line() int { return r.line }
+
+type csvWriter struct {
+	w      *csv.Writer
+	layout *fixedwidth.Layout
+	header bool
+}
+
+func (w *csvWriter) Write(values []string) error {
+	if w.header {
+		w.header = false
+		names := make([]string, len(w.layout.Fields))
+		for i, f := range w.layout.Fields {
+			names[i] = f.Name
+		}
+		if err := w.w.Write(names); err != nil {
+			return err
+		}
+	}
+	return w.w.Write(values)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonReader struct {
+	s      *bufio.Scanner
+	layout *fixedwidth.Layout
+	line   int
+}
+
+func (r *jsonReader) Read() ([]string, error) {
+	var line []byte
+	for len(bytes.TrimSpace(line)) == 0 {
+		if !r.s.Scan() {
+			if err := r.s.Err(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		line = r.s.Bytes()
+	}
+	r.line++
+
+	var obj map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil {
+		return nil, &recordError{line: r.line, err: err}
+	}
+
+	values := make([]string, len(r.layout.Fields))
+	for i, f := range r.layout.Fields {
+		switch v := obj[f.Name].(type) {
+		case nil:
+		case string:
+			values[i] = v
+		case json.Number:
+			values[i] = v.String()
+		case bool:
+			values[i] = strconv.FormatBool(v)
+		default:
+			return nil, &recordError{line: r.line, err: errors.New("field " + f.Name + ": unsupported JSON value")}
+		}
+	}
+	return values, nil
+}
+
+func (r *jsonReader) Line() int { return r.line }
+
+type jsonWriter struct {
+	w      io.Writer
+	layout *fixedwidth.Layout
+	buf    bytes.Buffer
+}
+
+// Write writes values as a JSON object. The keys are written in layout order, and
+// the values of int and decimal fields are written as JSON numbers.
+func (w *jsonWriter) Write(values []string) error {
+	w.buf.Reset()
+	w.buf.WriteByte('{')
+	for i, f := range w.layout.Fields {
+		if i > 0 {
+			w.buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(f.Name)
+		w.buf.Write(name)
+		w.buf.WriteByte(':')
+
+		switch {
+		case f.Type != fixedwidth.TypeInt && f.Type != fixedwidth.TypeDecimal:
+			s, _ := json.Marshal(values[i])
+			w.buf.Write(s)
+		case values[i] == "":
+			w.buf.WriteString("null")
+		default:
+			// Values that strconv accepts but JSON does not, such as "+1" or "Inf",
+			// are written as strings.
+			n, err := json.Marshal(json.Number(values[i]))
+			if err != nil {
+				n, _ = json.Marshal(values[i])
+			}
+			w.buf.Write(n)
+		}
+	}
+	w.buf.WriteString("}\n")
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *jsonWriter) Flush() error { return nil }
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testLayout = `{
+  "fields": [
+    {"name": "ID", "start": 1, "end": 5, "alignment": "right", "pad": "0", "type": "int"},
+    {"name": "Name", "start": 6, "end": 15},
+    {"name": "Grade", "start": 16, "end": 20, "type": "decimal"}
+  ]
+}`
+
+// writeFile writes data to a file in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConvert(t *testing.T) {
+	layout := writeFile(t, "layout.json", testLayout)
+	copybook := writeFile(t, "record.cpy", "       01  REC.\n           05  AMOUNT  PIC 9(5).\n           05  NAME    PIC X(4).\n")
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		in        string
+		want      string
+		shouldErr bool
+	}{
+		{
+			name: "fixed to csv",
+			args: []string{"-layout", layout},
+			in:   "00001Ian       99.50\n00002Jane      79.5 \n",
+			want: "ID,Name,Grade\n1,Ian,99.50\n2,Jane,79.5\n",
+		},
+		{
+			name: "fixed to tsv without header",
+			args: []string{"-layout", layout, "-to", "tsv", "-header=false"},
+			in:   "00001Ian       99.50\n",
+			want: "1\tIan\t99.50\n",
+		},
+		{
+			name: "fixed to tsv crlf",
+			args: []string{"-layout", layout, "-to", "tsv", "-header=false", "-terminator", "crlf"},
+			in:   "00001Ian       99.50\r\n",
+			want: "1\tIan\t99.50\n",
+		},
+		{
+			name: "fixed to jsonl",
+			args: []string{"-layout", layout, "-to", "jsonl"},
+			in:   "00001Ian       99.50\n00002Jane           \n",
+			want: `{"ID":1,"Name":"Ian","Grade":99.50}` + "\n" + `{"ID":2,"Name":"Jane","Grade":null}` + "\n",
+		},
+		{
+			name: "csv to fixed",
+			args: []string{"-layout", layout, "-from", "csv", "-to", "fixed"},
+			in:   "Name,ID,Extra\nIan,1,x\nJane,2,y\n",
+			want: "00001Ian            \n00002Jane           \n",
+		},
+		{
+			name: "jsonl to fixed",
+			args: []string{"-layout", layout, "-from", "jsonl", "-to", "fixed", "-terminator", "crlf"},
+			in:   `{"ID":1,"Name":"Ian","Grade":99.5}` + "\n\n" + `{"ID":2,"Name":"Jane"}` + "\n",
+			want: "00001Ian       99.5 \r\n00002Jane           \r\n",
+		},
+		{
+			name: "codepoints",
+			args: []string{"-layout", layout, "-codepoints", "-header=false"},
+			in:   "00001Iañ       99.50\n",
+			want: "1,Iañ,99.50\n",
+		},
+		{
+			name: "copybook zero to csv",
+			args: []string{"-copybook", copybook},
+			in:   "00000Ian \n00042Jane\n",
+			want: "AMOUNT,NAME\n0,Ian\n42,Jane\n",
+		},
+		{
+			name: "copybook zero to jsonl",
+			args: []string{"-copybook", copybook, "-to", "jsonl"},
+			in:   "00000Ian \n",
+			want: `{"AMOUNT":0,"NAME":"Ian"}` + "\n",
+		},
+		{
+			name: "copybook zero round trip",
+			args: []string{"-copybook", copybook, "-from", "csv", "-to", "fixed"},
+			in:   "AMOUNT,NAME\n0,Ian\n",
+			want: "00000Ian \n",
+		},
+		{
+			name:      "line too long",
+			args:      []string{"-layout", layout, "-header=false", "-on-error", "skip", "-max-line-length", "20"},
+			in:        "00001Ian       99.50\n00002Jane      79.5 xx\n",
+			want:      "1,Ian,99.50\n",
+			shouldErr: true,
+		},
+		{
+			name:      "invalid record fails",
+			args:      []string{"-layout", layout, "-header=false"},
+			in:        "00001Ian       99.50\n0000xJane      79.5 \n",
+			want:      "1,Ian,99.50\n",
+			shouldErr: true,
+		},
+		{
+			name: "invalid record skipped",
+			args: []string{"-layout", layout, "-header=false", "-on-error", "skip"},
+			in:   "0000xIan       99.50\n00002Jane      79.5 \n",
+			want: "2,Jane,79.5\n",
+		},
+		{
+			name: "invalid csv record skipped",
+			args: []string{"-layout", layout, "-from", "csv", "-to", "fixed", "-header=false", "-on-error", "warn"},
+			in:   "1,Ian\n2,Jane,79.5\n",
+			want: "00002Jane      79.5 \n",
+		},
+		{
+			name:      "missing layout",
+			args:      []string{},
+			in:        "",
+			shouldErr: true,
+		},
+		{
+			name:      "unknown format",
+			args:      []string{"-layout", layout, "-to", "xml"},
+			in:        "",
+			shouldErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := runConvert(tt.args, strings.NewReader(tt.in), &stdout, &stderr)
+			if tt.shouldErr != (err != nil) {
+				t.Errorf("runConvert() err want %v, have %v (%v)", tt.shouldErr, err != nil, err)
+			}
+			if have := stdout.String(); have != tt.want {
+				t.Errorf("runConvert() want %q, have %q", tt.want, have)
+			}
+		})
+	}
+}
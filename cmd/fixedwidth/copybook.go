@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+// parseCopybook builds a layout from the elementary items of a COBOL copybook.
+//
+// Only DISPLAY data is supported; items with a binary or packed USAGE, OCCURS, or
+// REDEFINES clauses are rejected. Group items contribute no fields of their own and
+// FILLER items only advance the position. Every level 01 item starts again at
+// position 1.
+//
+// Items whose picture contains only 9s are given the int type and are right aligned
+// and padded with zeros. All other items are strings. Signed and implied-decimal
+// pictures are left as strings because their text is not a plain number.
+func parseCopybook(r io.Reader) (*fixedwidth.Layout, error) {
+	src, err := readCopybookSource(r)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := new(fixedwidth.Layout)
+	names := make(map[string]int)
+	pos := 1
+	for _, stmt := range splitCopybookStatements(src) {
+		tokens := strings.Fields(stmt)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		level, err := strconv.Atoi(tokens[0])
+		if err != nil {
+			return nil, errors.New("invalid level number in " + strconv.Quote(stmt))
+		}
+		switch {
+		case level == 66 || level == 88:
+			// RENAMES and condition names do not describe storage.
+			continue
+		case level == 1:
+			pos = 1
+		}
+
+		name := "FILLER"
+		if len(tokens) > 1 && !isCopybookKeyword(tokens[1]) {
+			name = tokens[1]
+		}
+
+		var (
+			picture  string
+			separate bool
+		)
+		for i := 1; i < len(tokens); i++ {
+			switch tok := strings.ToUpper(tokens[i]); tok {
+			case "PIC", "PICTURE":
+				if i+1 < len(tokens) && strings.ToUpper(tokens[i+1]) == "IS" {
+					i++
+				}
+				if i+1 < len(tokens) {
+					picture = tokens[i+1]
+					i++
+				}
+			case "OCCURS", "REDEFINES":
+				return nil, errors.New(name + ": " + tok + " is not supported")
+			case "COMP", "COMP-1", "COMP-2", "COMP-3", "COMP-4", "COMP-5",
+				"COMPUTATIONAL", "COMPUTATIONAL-1", "COMPUTATIONAL-2", "COMPUTATIONAL-3",
+				"COMPUTATIONAL-4", "COMPUTATIONAL-5", "BINARY", "PACKED-DECIMAL":
+				return nil, errors.New(name + ": USAGE " + tok + " is not supported")
+			case "SEPARATE":
+				separate = true
+			}
+		}
+		if picture == "" {
+			// A group item.
+			continue
+		}
+
+		width, numeric, err := parsePicture(picture)
+		if err != nil {
+			return nil, errors.New(name + ": " + err.Error())
+		}
+		if separate {
+			width++
+		}
+
+		start := pos
+		pos += width
+		if strings.EqualFold(name, "FILLER") {
+			continue
+		}
+
+		// Names only need to be unique within a group in COBOL, so a later item that
+		// reuses a name is given a numeric suffix.
+		names[name]++
+		if n := names[name]; n > 1 {
+			name += "_" + strconv.Itoa(n)
+		}
+
+		field := fixedwidth.LayoutField{Name: name, Start: start, End: pos - 1}
+		if numeric && !separate {
+			field.Type = fixedwidth.TypeInt
+			field.Alignment = "right"
+			field.Pad = "0"
+		} else {
+			field.Type = fixedwidth.TypeString
+			field.Alignment = "left"
+		}
+		layout.Fields = append(layout.Fields, field)
+	}
+
+	if len(layout.Fields) == 0 {
+		return nil, errors.New("copybook has no elementary items")
+	}
+	return layout, nil
+}
+
+// readCopybookSource returns the program text of a copybook with comments and
+// the sequence and identification areas of fixed-format lines removed.
+func readCopybookSource(r io.Reader) (string, error) {
+	var b strings.Builder
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+
+		// Fixed-format lines start with a six character sequence area followed by
+		// an indicator column.
+		if len(line) > 6 && strings.TrimLeft(line[:6], "0123456789 ") == "" {
+			switch line[6] {
+			case '*', '/':
+				continue
+			}
+			line = line[7:]
+			if len(line) > 65 {
+				line = line[:65]
+			}
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "*") {
+			continue
+		}
+
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String(), s.Err()
+}
+
+// splitCopybookStatements splits src into statements. A statement ends with a
+// period followed by white space; periods inside a picture string do not end it.
+func splitCopybookStatements(src string) []string {
+	var stmts []string
+	start := 0
+	for i := 0; i < len(src); i++ {
+		if src[i] != '.' {
+			continue
+		}
+		if i+1 < len(src) && !unicode.IsSpace(rune(src[i+1])) {
+			continue
+		}
+		stmts = append(stmts, src[start:i])
+		start = i + 1
+	}
+	if rest := strings.TrimSpace(src[start:]); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+func isCopybookKeyword(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "PIC", "PICTURE", "USAGE", "VALUE", "VALUES", "OCCURS", "REDEFINES",
+		"SIGN", "JUSTIFIED", "JUST", "BLANK", "SYNC", "SYNCHRONIZED":
+		return true
+	}
+	return false
+}
+
+// parsePicture returns the number of characters occupied by a picture string and
+// whether it describes an unsigned integer.
+func parsePicture(picture string) (width int, numeric bool, err error) {
+	pic := strings.ToUpper(picture)
+	numeric = true
+	last := 0 // the width of the previous symbol
+	for i := 0; i < len(pic); i++ {
+		c := pic[i]
+		switch c {
+		case '(':
+			end := strings.IndexByte(pic[i:], ')')
+			if end < 0 {
+				return 0, false, errors.New("invalid picture " + picture)
+			}
+			n, err := strconv.Atoi(pic[i+1 : i+end])
+			if err != nil || n < 1 {
+				return 0, false, errors.New("invalid picture " + picture)
+			}
+			// The previous symbol is repeated n times in total.
+			width += last * (n - 1)
+			i += end
+			continue
+		case 'S', 'V', 'P':
+			// Sign, implied decimal point, and scaling symbols take no space.
+			numeric = false
+			last = 0
+			continue
+		case '9':
+		default:
+			numeric = false
+		}
+		width++
+		last = 1
+	}
+	if width == 0 {
+		return 0, false, errors.New("invalid picture " + picture)
+	}
+	return width, numeric, nil
+}
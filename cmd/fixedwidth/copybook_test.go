@@ -0,0 +1,84 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+func TestParseCopybook(t *testing.T) {
+	src := `
+000100* CUSTOMER MASTER RECORD
+000200 01  CUSTOMER-RECORD.
+000300     05  CUST-ID           PIC 9(6).
+000400     05  CUST-NAME.
+000500         10  FIRST-NAME    PIC X(10).
+000600         10  LAST-NAME     PIC X(10).
+000700     05  FILLER            PIC X(2).
+000800     05  BALANCE           PIC S9(7)V99.
+000900     05  STATUS            PIC X VALUE 'A'.
+001000         88  ACTIVE        VALUE 'A'.
+001100     05  RATE              PIC 99.99.
+`
+	layout, err := parseCopybook(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("parseCopybook() unexpected error: %v", err)
+	}
+
+	want := []fixedwidth.LayoutField{
+		{Name: "CUST-ID", Start: 1, End: 6, Alignment: "right", Pad: "0", Type: "int"},
+		{Name: "FIRST-NAME", Start: 7, End: 16, Alignment: "left", Type: "string"},
+		{Name: "LAST-NAME", Start: 17, End: 26, Alignment: "left", Type: "string"},
+		{Name: "BALANCE", Start: 29, End: 37, Alignment: "left", Type: "string"},
+		{Name: "STATUS", Start: 38, End: 38, Alignment: "left", Type: "string"},
+		{Name: "RATE", Start: 39, End: 43, Alignment: "left", Type: "string"},
+	}
+	if !reflect.DeepEqual(layout.Fields, want) {
+		t.Errorf("parseCopybook() want %+v, have %+v", want, layout.Fields)
+	}
+	if err := layout.Validate(); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestParseCopybook_unsupported(t *testing.T) {
+	for _, src := range []string{
+		"01 REC. 05 AMOUNT PIC S9(7) COMP-3.",
+		"01 REC. 05 ITEMS PIC X(5) OCCURS 10 TIMES.",
+		"01 REC. 05 BAD PIC X(.",
+		"01 REC.",
+	} {
+		if _, err := parseCopybook(strings.NewReader(src)); err == nil {
+			t.Errorf("parseCopybook(%q) expected error", src)
+		}
+	}
+}
+
+func TestParsePicture(t *testing.T) {
+	for _, tt := range []struct {
+		picture string
+		width   int
+		numeric bool
+	}{
+		{"X", 1, false},
+		{"X(10)", 10, false},
+		{"XXX", 3, false},
+		{"9(5)", 5, true},
+		{"999", 3, true},
+		{"S9(7)V99", 9, false},
+		{"9(3)V9(2)", 5, false},
+		{"Z,ZZ9.99", 8, false},
+		{"A(2)X(3)", 5, false},
+	} {
+		width, numeric, err := parsePicture(tt.picture)
+		if err != nil {
+			t.Errorf("parsePicture(%q) unexpected error: %v", tt.picture, err)
+			continue
+		}
+		if width != tt.width || numeric != tt.numeric {
+			t.Errorf("parsePicture(%q) want %v, %v, have %v, %v", tt.picture, tt.width, tt.numeric, width, numeric)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+func runDiff(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: fixedwidth diff [flags] -key field [-key field ...] old new")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Compare the records of two fixed-width files by key fields and report the")
+		fmt.Fprintln(fs.Output(), "records that were added, removed, or changed. Fields are compared by their")
+		fmt.Fprintln(fs.Output(), "decoded values, so changes in padding alone are not reported.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var (
+		lf     layoutFlags
+		cf     codecFlags
+		keys   stringsFlag
+		format = fs.String("format", "text", "output `format`: text or jsonl")
+		output = fs.String("o", "", "write output to `file` instead of stdout")
+	)
+	fs.Var(&keys, "key", "key `field`; may be repeated")
+	lf.register(fs)
+	cf.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 || len(keys) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var write func(w io.Writer, d fixedwidth.RecordDiff, layout *fixedwidth.Layout, keys []string) error
+	switch *format {
+	case "text":
+		write = writeTextDiff
+	case "jsonl":
+		write = writeJSONDiff
+	default:
+		return errors.New("invalid -format value " + strconv.Quote(*format))
+	}
+
+	layout, err := lf.load()
+	if err != nil {
+		return err
+	}
+
+	var decoders [2]*fixedwidth.Decoder
+	for i := range decoders {
+		f, err := os.Open(fs.Arg(i))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if decoders[i], err = cf.newDecoder(f); err != nil {
+			return err
+		}
+	}
+
+	diffs, err := fixedwidth.Diff(decoders[0], decoders[1], layout, keys...)
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(out)
+	for _, d := range diffs {
+		if err = write(bw, d, layout, keys); err != nil {
+			break
+		}
+	}
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// writeTextDiff writes d as a line of the form "changed ID=3 (lines 3, 2)", followed
+// by a line for each changed field.
+func writeTextDiff(w io.Writer, d fixedwidth.RecordDiff, layout *fixedwidth.Layout, keys []string) error {
+	var buf bytes.Buffer
+	buf.WriteString(d.Kind)
+	for i, name := range keys {
+		fmt.Fprintf(&buf, " %s=%s", name, strconv.Quote(d.Key[i]))
+	}
+	switch d.Kind {
+	case fixedwidth.DiffAdded:
+		fmt.Fprintf(&buf, " (line %d)\n", d.NewLine)
+	case fixedwidth.DiffRemoved:
+		fmt.Fprintf(&buf, " (line %d)\n", d.OldLine)
+	default:
+		fmt.Fprintf(&buf, " (lines %d, %d)\n", d.OldLine, d.NewLine)
+	}
+	for _, f := range d.Fields {
+		fmt.Fprintf(&buf, "  %s: %s -> %s\n", f.Name, strconv.Quote(f.Old), strconv.Quote(f.New))
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeJSONDiff writes d as a JSON object. Added and removed records are written in
+// full, in the same form as the jsonl output of convert.
+func writeJSONDiff(w io.Writer, d fixedwidth.RecordDiff, layout *fixedwidth.Layout, keys []string) error {
+	var buf bytes.Buffer
+	buf.WriteString(`{"kind":`)
+	writeJSONString(&buf, d.Kind)
+	buf.WriteString(`,"key":{`)
+	for i, name := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(&buf, name)
+		buf.WriteByte(':')
+		writeJSONString(&buf, d.Key[i])
+	}
+	buf.WriteByte('}')
+	if d.OldLine > 0 {
+		fmt.Fprintf(&buf, `,"old_line":%d`, d.OldLine)
+	}
+	if d.NewLine > 0 {
+		fmt.Fprintf(&buf, `,"new_line":%d`, d.NewLine)
+	}
+
+	var rec *fixedwidth.Record
+	switch d.Kind {
+	case fixedwidth.DiffAdded:
+		buf.WriteString(`,"record":`)
+		rec = d.New
+	case fixedwidth.DiffRemoved:
+		buf.WriteString(`,"record":`)
+		rec = d.Old
+	}
+	if rec != nil {
+		jw := &jsonWriter{w: &buf, layout: layout}
+		if err := jw.Write(rec.Values); err != nil {
+			return err
+		}
+		buf.Truncate(buf.Len() - 1) // drop the newline written by jsonWriter
+	}
+
+	if len(d.Fields) > 0 {
+		buf.WriteString(`,"fields":[`)
+		for i, f := range d.Fields {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.WriteString(`{"name":`)
+			writeJSONString(&buf, f.Name)
+			buf.WriteString(`,"old":`)
+			writeJSONString(&buf, f.Old)
+			buf.WriteString(`,"new":`)
+			writeJSONString(&buf, f.New)
+			buf.WriteByte('}')
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
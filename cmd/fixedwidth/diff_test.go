@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	layout := writeFile(t, "layout.json", testLayout)
+	old := writeFile(t, "old.txt", "00001Ann       79.5 \n00002Jane      79.5 \n00010Ian       99.50\n")
+	new := writeFile(t, "new.txt", "00010Ian       98.00\n00001Ann       79.5 \n00003John           \n")
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		want      string
+		shouldErr bool
+	}{
+		{
+			name: "text",
+			args: []string{"-layout", layout, "-key", "ID", old, new},
+			want: "" +
+				"changed ID=\"10\" (lines 3, 1)\n" +
+				"  Grade: \"99.50\" -> \"98.00\"\n" +
+				"added ID=\"3\" (line 3)\n" +
+				"removed ID=\"2\" (line 2)\n",
+		},
+		{
+			name: "jsonl",
+			args: []string{"-layout", layout, "-key", "ID", "-format", "jsonl", old, new},
+			want: "" +
+				`{"kind":"changed","key":{"ID":"10"},"old_line":3,"new_line":1,"fields":[{"name":"Grade","old":"99.50","new":"98.00"}]}` + "\n" +
+				`{"kind":"added","key":{"ID":"3"},"new_line":3,"record":{"ID":3,"Name":"John","Grade":null}}` + "\n" +
+				`{"kind":"removed","key":{"ID":"2"},"old_line":2,"record":{"ID":2,"Name":"Jane","Grade":79.5}}` + "\n",
+		},
+		{
+			name: "multiple keys",
+			args: []string{"-layout", layout, "-key", "Name", "-key", "Grade", old, new},
+			want: "" +
+				"added Name=\"Ian\" Grade=\"98.00\" (line 1)\n" +
+				"added Name=\"John\" Grade=\"\" (line 3)\n" +
+				"removed Name=\"Jane\" Grade=\"79.5\" (line 2)\n" +
+				"removed Name=\"Ian\" Grade=\"99.50\" (line 3)\n",
+		},
+		{name: "no key", args: []string{"-layout", layout, old, new}, shouldErr: true},
+		{name: "one file", args: []string{"-layout", layout, "-key", "ID", old}, shouldErr: true},
+		{name: "unknown key", args: []string{"-layout", layout, "-key", "Missing", old, new}, shouldErr: true},
+		{name: "invalid format", args: []string{"-layout", layout, "-key", "ID", "-format", "xml", old, new}, shouldErr: true},
+		{name: "missing file", args: []string{"-layout", layout, "-key", "ID", old, new + ".missing"}, shouldErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			err := runDiff(tt.args, strings.NewReader(""), &stdout, &stderr)
+			if tt.shouldErr != (err != nil) {
+				t.Fatalf("runDiff() err want %v, have %v", tt.shouldErr, err)
+			}
+			if !tt.shouldErr && stdout.String() != tt.want {
+				t.Errorf("runDiff() want\n%s\nhave\n%s", tt.want, stdout.String())
+			}
+		})
+	}
+}
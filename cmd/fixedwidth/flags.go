@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+// errUsage is returned by a command when its flags could not be parsed. The flag
+// package has already reported the problem.
+var errUsage = errors.New("usage")
+
+// parseFlags parses args with fs, mapping flag errors to errUsage.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// layoutFlags holds the flags used to load a layout.
+type layoutFlags struct {
+	layout   string
+	copybook string
+}
+
+func (f *layoutFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.layout, "layout", "", "read the record layout from a JSON `file`")
+	fs.StringVar(&f.copybook, "copybook", "", "read the record layout from a COBOL copybook `file`")
+}
+
+// load reads the layout named by the flags.
+func (f *layoutFlags) load() (*fixedwidth.Layout, error) {
+	var (
+		layout *fixedwidth.Layout
+		err    error
+	)
+	switch {
+	case f.layout != "" && f.copybook != "":
+		return nil, errors.New("only one of -layout and -copybook may be set")
+	case f.layout != "":
+		layout, err = readLayout(f.layout)
+	case f.copybook != "":
+		layout, err = readCopybook(f.copybook)
+	default:
+		return nil, errors.New("a layout is required, set -layout or -copybook")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := layout.Validate(); err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+func readLayout(name string) (*fixedwidth.Layout, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	layout := new(fixedwidth.Layout)
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(layout); err != nil {
+		return nil, errors.New(filepath.Base(name) + ": " + err.Error())
+	}
+	return layout, nil
+}
+
+func readCopybook(name string) (*fixedwidth.Layout, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	layout, err := parseCopybook(f)
+	if err != nil {
+		return nil, errors.New(filepath.Base(name) + ": " + err.Error())
+	}
+	return layout, nil
+}
+
+// codecFlags holds the flags that configure a fixedwidth.Decoder or
+// fixedwidth.Encoder.
+type codecFlags struct {
+	terminator    string
+	codepoints    bool
+	maxLineLength int
+}
+
+func (f *codecFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.terminator, "terminator", "lf", "line `terminator` of fixed-width data: lf, crlf, cr, any, or an escaped string;\nany reads lines ending in lf, crlf, or cr and writes lf;\nsort writes each line with its own terminator")
+	fs.BoolVar(&f.codepoints, "codepoints", false, "interpret layout positions as UTF-8 codepoints instead of bytes")
+	fs.IntVar(&f.maxLineLength, "max-line-length", 0, "maximum `length` in bytes of a line of fixed-width data (default 65535)")
+}
+
+func (f *codecFlags) lineTerminator() ([]byte, error) {
+	switch strings.ToLower(f.terminator) {
+	case "lf", "any":
+		return []byte("\n"), nil
+	case "crlf":
+		return []byte("\r\n"), nil
+	case "cr":
+		return []byte("\r"), nil
+	}
+	s, err := strconv.Unquote(`"` + f.terminator + `"`)
+	if err != nil || s == "" {
+		return nil, errors.New("invalid line terminator " + strconv.Quote(f.terminator))
+	}
+	return []byte(s), nil
+}
+
+func (f *codecFlags) newDecoder(r io.Reader) (*fixedwidth.Decoder, error) {
+	term, err := f.lineTerminator()
+	if err != nil {
+		return nil, err
+	}
+	dec := fixedwidth.NewDecoder(r)
+	if strings.ToLower(f.terminator) == "any" {
+		dec.SetUniversalNewlines(true)
+	} else {
+		dec.SetLineTerminator(term)
+	}
+	dec.SetUseCodepointIndices(f.codepoints)
+	dec.SetMaxLineLength(f.maxLineLength)
+	return dec, nil
+}
+
+func (f *codecFlags) newEncoder(w io.Writer) (*fixedwidth.Encoder, error) {
+	term, err := f.lineTerminator()
+	if err != nil {
+		return nil, err
+	}
+	enc := fixedwidth.NewEncoder(w)
+	enc.SetLineTerminator(term)
+	enc.SetUseCodepointIndices(f.codepoints)
+	return enc, nil
+}
+
+// openInput opens the named file, or returns stdin if name is empty or "-".
+func openInput(name string, stdin io.Reader) (io.ReadCloser, error) {
+	if name == "" || name == "-" {
+		return nopReadCloser{stdin}, nil
+	}
+	return os.Open(name)
+}
+
+// createOutput creates the named file, or returns stdout if name is empty or "-".
+func createOutput(name string, stdout io.Writer) (io.WriteCloser, error) {
+	if name == "" || name == "-" {
+		return nopWriteCloser{stdout}, nil
+	}
+	return os.Create(name)
+}
+
+type nopReadCloser struct {
+	io.Reader
+}
+
+func (nopReadCloser) Close() error { return nil }
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
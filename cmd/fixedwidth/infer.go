@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+func runInfer(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("infer", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: fixedwidth infer [flags] [file]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Propose a layout for a sample of fixed-width data. Input is read from file, or")
+		fmt.Fprintln(fs.Output(), "from stdin if no file is given.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var (
+		cf       codecFlags
+		output   = fs.String("format", "json", "output `format`: json or go")
+		name     = fs.String("name", "Record", "`name` of the generated Go struct")
+		maxLines = fs.Int("lines", 1000, "maximum number of `lines` to sample")
+	)
+	cf.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	term, err := cf.lineTerminator()
+	if err != nil {
+		return err
+	}
+
+	in, err := openInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var lines []string
+	s := bufio.NewScanner(in)
+	s.Buffer(nil, 1<<20)
+	s.Split(splitLines(term))
+	for len(lines) < *maxLines && s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	layout, err := fixedwidth.InferLayout(lines, cf.codepoints)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch *output {
+	case "json":
+		out, err = json.MarshalIndent(layout, "", "  ")
+		out = append(out, '\n')
+	case "go":
+		out, err = goStruct(*name, layout)
+	default:
+		return errors.New("unknown output format " + strconv.Quote(*output))
+	}
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(out)
+	return err
+}
+
+// splitLines returns a bufio.SplitFunc that splits its input on term.
+func splitLines(term []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, term); i >= 0 {
+			return i + len(term), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// goStruct returns the source of a Go struct type with `fixed` tags describing layout.
+func goStruct(name string, layout *fixedwidth.Layout) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "type %s struct {\n", goName(name))
+	for _, f := range layout.Fields {
+		typ := "string"
+		switch f.Type {
+		case fixedwidth.TypeInt:
+			typ = "int"
+		case fixedwidth.TypeDecimal:
+			typ = "float64"
+		}
+		fmt.Fprintf(&b, "\t%s %s `fixed:%q`", goName(f.Name), typ, fixedTag(f))
+		if f.Format != "" {
+			fmt.Fprintf(&b, " // %s", f.Format)
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// fixedTag returns the `fixed` struct tag equivalent to f.
+func fixedTag(f fixedwidth.LayoutField) string {
+	tag := strconv.Itoa(f.Start) + "," + strconv.Itoa(f.End)
+	if f.Alignment == "" && f.Pad == "" {
+		return tag
+	}
+	alignment := f.Alignment
+	if alignment == "" {
+		alignment = "default"
+	}
+	tag += "," + alignment
+	switch f.Pad {
+	case "", " ":
+	case "_":
+		tag += ",__"
+	default:
+		tag += "," + f.Pad
+	}
+	return tag
+}
+
+// goName converts a field name such as CUST-ID or first_name to an exported Go
+// identifier such as CustID or FirstName.
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, p := range parts {
+		switch upper := strings.ToUpper(p); {
+		case upper == "ID" || upper == "URL" || upper == "SSN":
+			b.WriteString(upper)
+		case p == upper:
+			b.WriteString(p[:1] + strings.ToLower(p[1:]))
+		default:
+			r := []rune(p)
+			r[0] = unicode.ToUpper(r[0])
+			b.WriteString(string(r))
+		}
+	}
+	s := b.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "F" + s
+	}
+	return s
+}
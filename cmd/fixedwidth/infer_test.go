@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+func TestInfer(t *testing.T) {
+	in := "00001Ian       99.50\n00042Jane       9.50\n"
+
+	t.Run("json", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if err := runInfer(nil, strings.NewReader(in), &stdout, &stderr); err != nil {
+			t.Fatalf("runInfer() unexpected error: %v", err)
+		}
+		want := `{
+  "fields": [
+    {
+      "name": "Field1",
+      "start": 1,
+      "end": 5,
+      "alignment": "right",
+      "pad": "0",
+      "type": "int"
+    },
+    {
+      "name": "Field2",
+      "start": 6,
+      "end": 9,
+      "alignment": "left",
+      "type": "string"
+    },
+    {
+      "name": "Field3",
+      "start": 10,
+      "end": 20,
+      "alignment": "right",
+      "type": "decimal"
+    }
+  ]
+}
+`
+		if have := stdout.String(); have != want {
+			t.Errorf("runInfer() want\n%s\nhave\n%s", want, have)
+		}
+	})
+
+	t.Run("go", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if err := runInfer([]string{"-format", "go", "-name", "person"}, strings.NewReader(in), &stdout, &stderr); err != nil {
+			t.Fatalf("runInfer() unexpected error: %v", err)
+		}
+		want := "type Person struct {\n" +
+			"\tField1 int     `fixed:\"1,5,right,0\"`\n" +
+			"\tField2 string  `fixed:\"6,9,left\"`\n" +
+			"\tField3 float64 `fixed:\"10,20,right\"`\n" +
+			"}\n"
+		if have := stdout.String(); have != want {
+			t.Errorf("runInfer() want\n%s\nhave\n%s", want, have)
+		}
+	})
+}
+
+func TestFixedTag(t *testing.T) {
+	for _, tt := range []struct {
+		field fixedwidth.LayoutField
+		want  string
+	}{
+		{fixedwidth.LayoutField{Start: 1, End: 5}, "1,5"},
+		{fixedwidth.LayoutField{Start: 1, End: 5, Alignment: "right"}, "1,5,right"},
+		{fixedwidth.LayoutField{Start: 1, End: 5, Pad: "#"}, "1,5,default,#"},
+		{fixedwidth.LayoutField{Start: 1, End: 5, Alignment: "left", Pad: "_"}, "1,5,left,__"},
+	} {
+		if have := fixedTag(tt.field); have != tt.want {
+			t.Errorf("fixedTag(%+v) want %q, have %q", tt.field, tt.want, have)
+		}
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for name, want := range map[string]string{
+		"CUST-ID":    "CustID",
+		"first_name": "FirstName",
+		"Field1":     "Field1",
+		"1st":        "F1st",
+		"zip code":   "ZipCode",
+	} {
+		if have := goName(name); have != want {
+			t.Errorf("goName(%q) want %q, have %q", name, want, have)
+		}
+	}
+}
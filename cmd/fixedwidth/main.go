@@ -0,0 +1,85 @@
+// Command fixedwidth converts fixed-width data to and from other formats.
+//
+// Usage:
+//
+//	fixedwidth <command> [flags] [args]
+//
+// The commands are:
+//
+//	convert    convert between fixed-width, CSV, TSV, and JSON Lines
+//	diff       compare the records of two fixed-width files by key fields
+//	infer      propose a layout for a sample of fixed-width data
+//	sort       sort fixed-width records by key fields
+//
+// The layout of the fixed-width data is read from a JSON file with the -layout flag
+// or from a COBOL copybook with the -copybook flag. A JSON layout has the form:
+//
+//	{
+//	  "fields": [
+//	    {"name": "ID", "start": 1, "end": 5, "alignment": "right", "pad": "0", "type": "int"},
+//	    {"name": "Name", "start": 6, "end": 25}
+//	  ]
+//	}
+//
+// Run "fixedwidth <command> -h" for the flags accepted by a command.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+var commands = []command{
+	{"convert", "convert between fixed-width, CSV, TSV, and JSON Lines", runConvert},
+	{"diff", "compare the records of two fixed-width files by key fields", runDiff},
+	{"infer", "propose a layout for a sample of fixed-width data", runInfer},
+	{"sort", "sort fixed-width records by key fields", runSort},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "-help" || name == "--help" {
+		usage(os.Stdout)
+		return
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(os.Args[2:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+			if err != errUsage {
+				fmt.Fprintf(os.Stderr, "fixedwidth %s: %v\n", name, err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "fixedwidth: unknown command %q\n", name)
+	usage(os.Stderr)
+	os.Exit(2)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: fixedwidth <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "fixedwidth <command> -h" for the flags accepted by a command.`)
+}
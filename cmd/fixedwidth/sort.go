@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+func runSort(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("sort", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: fixedwidth sort [flags] -key key [-key key ...] [file]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Sort fixed-width records by one or more key fields. Records are written")
+		fmt.Fprintln(fs.Output(), "unchanged. Input is read from file, or from stdin if no file is given.")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "A key is a layout field name or a start-end position, optionally followed")
+		fmt.Fprintln(fs.Output(), "by :desc for descending order and :num or :str to compare values as numbers")
+		fmt.Fprintln(fs.Output(), "or strings, for example Account, Date:desc, or 11-18:num. Fields with the int")
+		fmt.Fprintln(fs.Output(), "or decimal type are compared as numbers by default.")
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+
+	var (
+		lf      layoutFlags
+		cf      codecFlags
+		keys    stringsFlag
+		memory  = fs.Int("memory", 64, "memory budget in `MiB`; larger inputs are sorted using temporary files")
+		tempDir = fs.String("tmpdir", "", "create temporary files in `dir`")
+		output  = fs.String("o", "", "write output to `file` instead of stdout")
+	)
+	fs.Var(&keys, "key", "sort `key`; may be repeated")
+	lf.register(fs)
+	cf.register(fs)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 || len(keys) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	// A layout is only needed to look up keys by name.
+	var layout *fixedwidth.Layout
+	if lf.layout != "" || lf.copybook != "" {
+		var err error
+		if layout, err = lf.load(); err != nil {
+			return err
+		}
+	}
+	sortKeys := make([]fixedwidth.SortKey, len(keys))
+	for i, k := range keys {
+		var err error
+		if sortKeys[i], err = parseSortKey(k, layout); err != nil {
+			return err
+		}
+	}
+
+	in, err := openInput(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	dec, err := cf.newDecoder(in)
+	if err != nil {
+		return err
+	}
+
+	out, err := createOutput(*output, stdout)
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(out)
+
+	err = fixedwidth.Sort(bw, dec, sortKeys, &fixedwidth.SortOptions{
+		MemoryLimit: *memory << 20,
+		TempDir:     *tempDir,
+	})
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// parseSortKey parses a key of the form name[:option...] or start-end[:option...].
+func parseSortKey(s string, layout *fixedwidth.Layout) (fixedwidth.SortKey, error) {
+	parts := strings.Split(s, ":")
+
+	var (
+		key fixedwidth.SortKey
+		err error
+	)
+	if start, end, ok := strings.Cut(parts[0], "-"); ok && isDigits(start) && isDigits(end) {
+		key.Start, _ = strconv.Atoi(start)
+		key.End, _ = strconv.Atoi(end)
+		if key.Start < 1 || key.End < key.Start {
+			return key, errors.New("invalid key position " + strconv.Quote(parts[0]))
+		}
+	} else {
+		if layout == nil {
+			return key, errors.New("key " + strconv.Quote(parts[0]) + " is not a position and no layout is set")
+		}
+		if key, err = layout.SortKey(parts[0]); err != nil {
+			return key, errors.New("unknown key field " + strconv.Quote(parts[0]))
+		}
+	}
+
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "asc":
+			key.Descending = false
+		case "desc":
+			key.Descending = true
+		case "num":
+			key.Numeric = true
+		case "str":
+			key.Numeric = false
+		default:
+			return key, errors.New("invalid key option " + strconv.Quote(opt) + " in " + strconv.Quote(s))
+		}
+	}
+	return key, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// stringsFlag is a flag.Value that collects the values of a repeated flag.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	layout := writeFile(t, "layout.json", testLayout)
+	in := "00002Jane      79.5 \n00010Ian       99.50\n00001Ann       79.5 \n"
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		in        string
+		want      string
+		shouldErr bool
+	}{
+		{
+			name: "numeric field",
+			args: []string{"-layout", layout, "-key", "ID"},
+			want: "00001Ann       79.5 \n00002Jane      79.5 \n00010Ian       99.50\n",
+		},
+		{
+			name: "multiple keys",
+			args: []string{"-layout", layout, "-key", "Grade:desc", "-key", "Name"},
+			want: "00010Ian       99.50\n00001Ann       79.5 \n00002Jane      79.5 \n",
+		},
+		{
+			name: "position",
+			args: []string{"-key", "6-15:str"},
+			want: "00001Ann       79.5 \n00010Ian       99.50\n00002Jane      79.5 \n",
+		},
+		{
+			name: "descending with options",
+			args: []string{"-key", "1-5:num:desc", "-memory", "1", "-tmpdir", t.TempDir()},
+			want: "00010Ian       99.50\n00002Jane      79.5 \n00001Ann       79.5 \n",
+		},
+		{
+			name: "any terminator",
+			args: []string{"-key", "6-15", "-terminator", "any"},
+			in:   "00002Jane      79.5 \r\n00010Ian       99.50\r00001Ann       79.5 \n",
+			want: "00001Ann       79.5 \n00010Ian       99.50\r00002Jane      79.5 \r\n",
+		},
+		{name: "no key", args: []string{"-layout", layout}, shouldErr: true},
+		{name: "name without layout", args: []string{"-key", "ID"}, shouldErr: true},
+		{name: "unknown field", args: []string{"-layout", layout, "-key", "Missing"}, shouldErr: true},
+		{name: "invalid position", args: []string{"-key", "5-1"}, shouldErr: true},
+		{name: "invalid option", args: []string{"-key", "1-5:up"}, shouldErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.in == "" {
+				tt.in = in
+			}
+			var stdout, stderr bytes.Buffer
+			err := runSort(tt.args, strings.NewReader(tt.in), &stdout, &stderr)
+			if tt.shouldErr != (err != nil) {
+				t.Fatalf("runSort() err want %v, have %v", tt.shouldErr, err)
+			}
+			if !tt.shouldErr && stdout.String() != tt.want {
+				t.Errorf("runSort() want\n%q\nhave\n%q", tt.want, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package fixedwidth
+
+import (
+	"context"
+	"strconv"
+)
+
+// A ContextError is returned by DecodeContext and EncodeContext when their context is
+// done before all values have been decoded or encoded. It wraps the error of the
+// context.
+type ContextError struct {
+	// Line is the number of lines read from the input when decoding, or the number of
+	// lines written by the call when encoding. The values before it have been stored
+	// or written.
+	Line int
+	Err  error
+}
+
+func (e *ContextError) Error() string {
+	return "fixedwidth: stopped after line " + strconv.Itoa(e.Line) + ": " + e.Err.Error()
+}
+
+func (e *ContextError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeContext is like Decode, but stops with a *ContextError if ctx is done. The
+// context is checked before every line, which allows the decoding of a slice to be
+// canceled partway through the input. A read that blocks is not interrupted.
+func (d *Decoder) DecodeContext(ctx context.Context, v interface{}) error {
+	d.ctx = ctx
+	defer func() { d.ctx = nil }()
+	if err := d.checkContext(); err != nil {
+		return err
+	}
+	return d.Decode(v)
+}
+
+// checkContext returns a *ContextError if the context passed to DecodeContext is done.
+func (d *Decoder) checkContext() error {
+	if d.ctx == nil {
+		return nil
+	}
+	if err := d.ctx.Err(); err != nil {
+		return &ContextError{Line: d.lineNum, Err: err}
+	}
+	return nil
+}
+
+// EncodeContext is like Encode, but stops with a *ContextError if ctx is done. The
+// context is checked before every line, which allows the encoding of a slice to be
+// canceled partway through. The lines written before the context was done are flushed
+// before the *ContextError is returned.
+func (e *Encoder) EncodeContext(ctx context.Context, v interface{}) error {
+	e.ctx = ctx
+	defer func() { e.ctx = nil }()
+	if err := e.checkContext(0); err != nil {
+		return err
+	}
+	err := e.Encode(v)
+	if _, ok := err.(*ContextError); ok {
+		if ferr := e.w.Flush(); ferr != nil {
+			return ferr
+		}
+	}
+	return err
+}
+
+// checkContext returns a *ContextError if the context passed to EncodeContext is done.
+// n is the number of lines written so far.
+func (e *Encoder) checkContext(n int) error {
+	if e.ctx == nil {
+		return nil
+	}
+	if err := e.ctx.Err(); err != nil {
+		return &ContextError{Line: n, Err: err}
+	}
+	return nil
+}
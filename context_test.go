@@ -0,0 +1,97 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_DecodeContext(t *testing.T) {
+	type S struct {
+		ID int `fixed:"1,3"`
+	}
+
+	for _, concurrency := range []int{0, 2} {
+		ctx, cancel := context.WithCancel(context.Background())
+		d := NewDecoder(strings.NewReader("001\n002\n003\n004\n005\n"))
+		d.SetConcurrency(concurrency)
+		// Cancel while the third line is read, as a disconnecting client would.
+		d.SetLineFilter(func(line []byte) bool {
+			if string(line) == "003" {
+				cancel()
+			}
+			return true
+		})
+		var have []S
+		err := d.DecodeContext(ctx, &have)
+		var ctxErr *ContextError
+		if !errors.Is(err, context.Canceled) || !errors.As(err, &ctxErr) || ctxErr.Line != 3 {
+			t.Errorf("DecodeContext() with concurrency %d want error after line 3, have %v", concurrency, err)
+		}
+		if want := []S{{1}, {2}, {3}}; !reflect.DeepEqual(have, want) {
+			t.Errorf("DecodeContext() with concurrency %d want %v, have %v", concurrency, want, have)
+		}
+
+		// The context does not apply to later calls.
+		var rest []S
+		if err := d.Decode(&rest); err != nil || len(rest) != 2 {
+			t.Errorf("Decode() after cancellation want 2 values, have %v, %v", rest, err)
+		}
+	}
+
+	t.Run("canceled before decoding", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		d := NewDecoder(strings.NewReader("001\n"))
+		var s S
+		if err := d.DecodeContext(ctx, &s); !errors.Is(err, context.Canceled) || d.LineNumber() != 0 {
+			t.Errorf("DecodeContext() want context.Canceled before line 1, have %v, line %d", err, d.LineNumber())
+		}
+	})
+
+	t.Run("not canceled", func(t *testing.T) {
+		var have []S
+		if err := NewDecoder(strings.NewReader("001\n002\n")).DecodeContext(context.Background(), &have); err != nil {
+			t.Fatalf("DecodeContext() unexpected error: %v", err)
+		}
+		if want := []S{{1}, {2}}; !reflect.DeepEqual(have, want) {
+			t.Errorf("DecodeContext() want %v, have %v", want, have)
+		}
+	})
+}
+
+// cancelingMarshaler cancels a context when it is marshaled.
+type cancelingMarshaler struct {
+	cancel context.CancelFunc
+}
+
+func (m cancelingMarshaler) MarshalFixedWidth() ([]byte, error) {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return []byte("x"), nil
+}
+
+func TestEncoder_EncodeContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var buf bytes.Buffer
+	e := NewEncoder(&buf)
+	err := e.EncodeContext(ctx, []cancelingMarshaler{{}, {cancel}, {}, {}})
+	var ctxErr *ContextError
+	if !errors.Is(err, context.Canceled) || !errors.As(err, &ctxErr) || ctxErr.Line != 2 {
+		t.Errorf("EncodeContext() want error after line 2, have %v", err)
+	}
+	// The lines written before cancellation are flushed.
+	if want := "x\nx\n"; buf.String() != want {
+		t.Errorf("EncodeContext() want %q, have %q", want, buf.String())
+	}
+	if err := e.EncodeContext(context.Background(), []cancelingMarshaler{{}, {}}); err != nil {
+		t.Fatalf("EncodeContext() unexpected error: %v", err)
+	}
+	if want := "x\nx\nx\nx"; buf.String() != want {
+		t.Errorf("EncodeContext() want %q, have %q", want, buf.String())
+	}
+}
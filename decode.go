@@ -3,17 +3,27 @@ package fixedwidth
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding"
 	"errors"
 	"io"
 	"reflect"
 	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ianlopshire/go-fixedwidth/internal/parse"
 )
 
 var (
-	// ErrTooLong indicates a line was too long to decode. Currently, the maximum
-	// decodable line length is bufio.MaxScanTokenSize-1.
+	// ErrTooLong indicates a line was too long to decode. The Decoder returns it wrapped
+	// in a *LineTooLongError. By default, the maximum decodable line length is
+	// bufio.MaxScanTokenSize-1; see Decoder.SetMaxLineLength.
 	ErrTooLong = bufio.ErrTooLong
+
+	// ErrShortRecord indicates that the input ended partway through a record when a
+	// record length is set with SetRecordLength.
+	ErrShortRecord = errors.New("fixedwidth: input ends with a short record")
 )
 
 // Unmarshal parses fixed width encoded data and stores the
@@ -29,9 +39,74 @@ type Decoder struct {
 	lineTerminator      []byte
 	done                bool
 	useCodepointIndices bool
+	concurrency         int
+
+	// recordLength is the length of each record when the input is made up of records
+	// of a fixed length rather than of terminated lines, 0 otherwise.
+	recordLength int
+
+	// minLineLength and maxLineLength bound the length of lines when set by
+	// SetLineLengthRange. A maxLineLength of 0 means lines are not checked.
+	minLineLength, maxLineLength int
+
+	// lineTerminators holds the terminators set by SetLineTerminators, if there is more
+	// than one. scanTerminator is the terminator of the last line split by scan, and
+	// terminator that of the last line returned by nextLine. Both are nil for a line
+	// that is not terminated.
+	lineTerminators  [][]byte
+	terminatorStarts [256]bool // whether a byte starts one of lineTerminators
+	scanTerminator   []byte
+	terminator       []byte
+
+	// lineNum is the number of lines that have been read from the input, and offset the
+	// number of bytes up to the end of the last of them. scanOffset is the number of
+	// bytes split by the scanner, which may be ahead of offset.
+	lineNum    int
+	offset     int64
+	scanOffset int64
+
+	// lineLimit is the maximum line length set by SetMaxLineLength or Buffer, 0 if the
+	// default applies, and buf the initial buffer set by Buffer. scanStarted is set once
+	// the scanner has been configured.
+	lineLimit   int
+	buf         []byte
+	scanStarted bool
+
+	// keepBOM is set by SetStripBOM to leave a byte order mark at the start of the
+	// input in place. bomChecked is set once the start of the input has been checked,
+	// and strippedBOM if a byte order mark was stripped from it.
+	keepBOM, bomChecked, strippedBOM bool
+
+	// lineFilter reports whether a line should be decoded when set by SetLineFilter or
+	// SetRecordFilter.
+	lineFilter func(line []byte) bool
+
+	// skipHeader and skipTrailer are the number of lines to skip at the start and the end
+	// of the input. ahead holds the lines read ahead to recognize the trailer, and spare
+	// is a buffer that can be reused for the next one. scanDone is set once the scanner
+	// has reached the end of the input.
+	skipHeader, skipTrailer int
+	ahead                   []scannedLine
+	spare                   []byte
+	scanDone                bool
+
+	commentPrefixes [][]byte
+	skipBlankLines  bool
+	skipHandler     func(SkippedLine)
+
+	// fieldFilter reports whether a struct field should be decoded when set by
+	// SetFieldFilter or SetFields.
+	fieldFilter func(name string) bool
+
+	// ctx is the context passed to DecodeContext while it runs, nil otherwise.
+	ctx context.Context
+
+	// emitted is the position after the value passed to the caller last while lines are
+	// decoded in parallel, nil otherwise. It is only accessed by the caller's goroutine.
+	emitted *Checkpoint
 
 	lastType       reflect.Type
-	lastValuSetter valueSetter
+	lastValuSetter lineSetter
 }
 
 // NewDecoder returns a new decoder that reads from r.
@@ -40,10 +115,21 @@ func NewDecoder(r io.Reader) *Decoder {
 		scanner:        bufio.NewScanner(r),
 		lineTerminator: []byte("\n"),
 	}
-	dec.scanner.Split(dec.scan)
+	dec.scanner.Split(dec.split)
 	return dec
 }
 
+// Unmarshaler is the interface implemented by types that can unmarshal a fixed-width
+// line of themselves. The line is passed without its terminator. UnmarshalFixedWidth
+// must copy the data if it wishes to retain the data after returning.
+//
+// Unless codepoint indices are in use, the Decoder calls UnmarshalFixedWidth instead
+// of decoding the fields of a struct using reflection. The fixedwidth-gen command
+// generates implementations of Unmarshaler.
+type Unmarshaler interface {
+	UnmarshalFixedWidth(data []byte) error
+}
+
 // An InvalidUnmarshalError describes an invalid argument passed to Unmarshal.
 // (The argument to Unmarshal must be a non-nil pointer.)
 type InvalidUnmarshalError struct {
@@ -69,14 +155,15 @@ type UnmarshalTypeError struct {
 	Struct string       // name of the struct type containing the field
 	Field  string       // name of the field holding the Go value
 	Cause  error        // original error
+	Line   int          // line of the input containing the value, 0 if unknown
 }
 
 func (e *UnmarshalTypeError) Error() string {
 	var s string
-	if e.Struct != "" || e.Field != "" {
-		s = "fixedwidth: cannot unmarshal " + e.Value + " into Go struct field " + e.Struct + "." + e.Field + " of type " + e.Type.String()
+	if e.Line > 0 {
+		s = "fixedwidth: line " + strconv.Itoa(e.Line) + ":" + strings.TrimPrefix(e.errorMessage(), "fixedwidth:")
 	} else {
-		s = "fixedwidth: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+		s = e.errorMessage()
 	}
 	if e.Cause != nil {
 		return s + ":" + e.Cause.Error()
@@ -84,11 +171,37 @@ func (e *UnmarshalTypeError) Error() string {
 	return s
 }
 
+func (e *UnmarshalTypeError) errorMessage() string {
+	if e.Struct != "" || e.Field != "" {
+		return "fixedwidth: cannot unmarshal " + e.Value + " into Go struct field " + e.Struct + "." + e.Field + " of type " + e.Type.String()
+	}
+	return "fixedwidth: cannot unmarshal " + e.Value + " into Go value of type " + e.Type.String()
+}
+
+// A LineLengthError is returned by the Decoder when a line is not of the length set by
+// SetLineLengthRange, and by the Encoder when a line does not fit in the length set by
+// SetLineLength or SetRecordLength.
+type LineLengthError struct {
+	Line   int // line of the input, or of the values passed to Encode, starting at 1
+	Length int // length of the line, excluding its terminator
+	Min    int // minimum allowed length
+	Max    int // maximum allowed length
+}
+
+func (e *LineLengthError) Error() string {
+	s := "fixedwidth: line " + strconv.Itoa(e.Line) + ": length " + strconv.Itoa(e.Length)
+	if e.Min == e.Max {
+		return s + ", want " + strconv.Itoa(e.Min)
+	}
+	return s + ", want between " + strconv.Itoa(e.Min) + " and " + strconv.Itoa(e.Max)
+}
+
 // SetUseCodepointIndices configures `Decoder` on whether the indices in the
 // `fixedwidth` struct tags are expressed in terms of bytes (the default
 // behavior) or in terms of UTF-8 decoded codepoints.
 func (d *Decoder) SetUseCodepointIndices(use bool) {
 	d.useCodepointIndices = use
+	d.lastType = nil
 }
 
 // Decode reads from its input and stores the decoded data to the value
@@ -101,8 +214,9 @@ func (d *Decoder) SetUseCodepointIndices(use bool) {
 // In the case that v points to a slice value, Decode will read until
 // the end of its input.
 //
-// Currently, the maximum decodable line length is bufio.MaxScanTokenSize-1. ErrTooLong
-// is returned if a line is encountered that too long to decode.
+// By default, the maximum decodable line length is bufio.MaxScanTokenSize-1. A
+// *LineTooLongError wrapping ErrTooLong is returned if a line is encountered that is
+// too long to decode.
 func (d *Decoder) Decode(v interface{}) error {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
@@ -125,14 +239,29 @@ func (d *Decoder) Decode(v interface{}) error {
 
 func (d *Decoder) readLines(v reflect.Value) (err error) {
 	ct := v.Type().Elem()
+	if d.concurrency > 1 {
+		return d.decodeParallel(ct, func(nv reflect.Value) error {
+			appendZero(v).Set(nv)
+			return nil
+		})
+	}
+	isNil := v.IsNil()
 	for {
-		nv := reflect.New(ct).Elem()
-		err, ok := d.readLine(nv)
-		if err != nil {
+		// Decode directly into a new element of the slice, which avoids allocating a
+		// value for every line. The element is removed again if no line was decoded.
+		if err := d.checkContext(); err != nil {
 			return err
 		}
-		if ok {
-			v.Set(reflect.Append(v, nv))
+		n := v.Len()
+		err, ok := d.readLine(appendZero(v))
+		if err != nil || !ok {
+			v.SetLen(n)
+			if n == 0 && isNil {
+				v.Set(reflect.Zero(v.Type()))
+			}
+		}
+		if err != nil {
+			return err
 		}
 		if d.done {
 			break
@@ -141,86 +270,224 @@ func (d *Decoder) readLines(v reflect.Value) (err error) {
 	return nil
 }
 
+// appendZero appends the zero value of its element type to the slice v and returns the
+// new element. Unlike reflect.Append, it does not allocate if v has spare capacity.
+func appendZero(v reflect.Value) reflect.Value {
+	n := v.Len()
+	if n < v.Cap() {
+		v.SetLen(n + 1)
+		e := v.Index(n)
+		e.Set(reflect.Zero(e.Type()))
+		return e
+	}
+	v.Set(reflect.Append(v, reflect.Zero(v.Type().Elem())))
+	return v.Index(n)
+}
+
+// SetConcurrency sets the number of goroutines used to decode lines when Decode is
+// called with a pointer to a slice. Lines are still appended to the slice in the order
+// they appear in the input. Values of n less than 2 disable concurrent decoding, which
+// is the default.
+//
+// Concurrent decoding only pays off when decoding a line is expensive compared to
+// reading it, such as for wide records with many fields.
+func (d *Decoder) SetConcurrency(n int) {
+	d.concurrency = n
+}
+
 // SetLineTerminator sets the character(s) that will be used to terminate lines.
 //
 // The default value is "\n".
 func (d *Decoder) SetLineTerminator(lineTerminator []byte) {
 	if len(lineTerminator) > 0 {
 		d.lineTerminator = lineTerminator
+		d.lineTerminators = nil
 	}
 }
 
+// SetLineLengthRange configures the Decoder to reject lines that are shorter than min or
+// longer than max with a *LineLengthError. Set min and max to the same value to require
+// lines of an exact length. Lengths are in bytes, or in codepoints if codepoint indices
+// are in use, and exclude the line terminator.
+//
+// By default, short lines are decoded as if the missing fields were empty and data past
+// the last field is ignored. A max of 0 restores the default behavior.
+func (d *Decoder) SetLineLengthRange(min, max int) {
+	d.minLineLength, d.maxLineLength = min, max
+}
+
+// SetRecordLength configures the Decoder to read records of exactly n bytes, or n
+// codepoints if codepoint indices are in use, that are not separated by a line
+// terminator. This is the layout of blocked files such as those with 80 or 94 byte
+// records. The line terminator is ignored while a record length is set.
+//
+// If the input ends partway through a record, ErrShortRecord is returned. A value of n
+// less than 1 restores the default behavior of reading terminated lines.
+func (d *Decoder) SetRecordLength(n int) {
+	d.recordLength = n
+}
+
+// split is the split function of the scanner. It counts the bytes read from the input
+// and enforces the maximum line length. The scanner's buffer bounds lines that are
+// followed by a terminator, but a final line without one may still be longer.
+func (d *Decoder) split(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	advance, token, err = d.scan(data, atEOF)
+	if err != nil {
+		return 0, nil, err
+	}
+	if d.lineLimit > 0 && len(token) > d.lineLimit {
+		return 0, nil, ErrTooLong
+	}
+	d.scanOffset += int64(advance)
+	return advance, token, nil
+}
+
 func (d *Decoder) scan(data []byte, atEOF bool) (advance int, token []byte, err error) {
 	if atEOF && len(data) == 0 {
 		return 0, nil, nil
 	}
+	if !d.keepBOM && !d.bomChecked {
+		if n, ok, err := d.scanBOM(data, atEOF); !ok || n > 0 {
+			return n, nil, err
+		}
+	}
+	if d.recordLength > 0 {
+		d.scanTerminator = nil
+		return d.scanRecord(data, atEOF)
+	}
+	if d.lineTerminators != nil {
+		return d.scanTerminators(data, atEOF)
+	}
 	if i := bytes.Index(data, d.lineTerminator); i >= 0 {
 		// We have a full newline-terminated line.
+		d.scanTerminator = d.lineTerminator
 		return i + len(d.lineTerminator), data[0:i], nil
 	}
 	// If we're at EOF, we have a final, non-terminated line. Return it.
 	if atEOF {
+		d.scanTerminator = nil
 		return len(data), data, nil
 	}
 	// Request more data.
 	return 0, nil, nil
 }
 
+// scanRecord is the split function used when a record length is set. data is not
+// empty.
+func (d *Decoder) scanRecord(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if !d.useCodepointIndices {
+		if len(data) >= d.recordLength {
+			return d.recordLength, data[:d.recordLength], nil
+		}
+	} else {
+		var i, n int
+		for n < d.recordLength && i < len(data) && utf8.FullRune(data[i:]) {
+			_, size := utf8.DecodeRune(data[i:])
+			i += size
+			n++
+		}
+		if n == d.recordLength {
+			return i, data[:i], nil
+		}
+	}
+	if atEOF {
+		return 0, nil, ErrShortRecord
+	}
+	// Request more data.
+	return 0, nil, nil
+}
+
 // readLine reads the next line of data. False is returned if there is no remaining data
 // to read.
 func (d *Decoder) readLine(v reflect.Value) (err error, ok bool) {
-	ok = d.scanner.Scan()
-	if !ok {
-		if d.scanner.Err() != nil {
-			return d.scanner.Err(), false
-		}
-
-		d.done = true
-		return nil, false
+	t := v.Type()
+	if t != d.lastType {
+		d.lastType = t
+		d.lastValuSetter = d.valueSetter(t)
 	}
+	return d.readLineWith(v, d.lastValuSetter)
+}
 
-	line := string(d.scanner.Bytes())
-
-	rawValue, err := newRawValue(line, d.useCodepointIndices)
-	if err != nil {
+// readLineWith is like readLine but decodes the line using the provided lineSetter.
+func (d *Decoder) readLineWith(v reflect.Value, setter lineSetter) (err error, ok bool) {
+	line, ok, err := d.nextLine()
+	if !ok {
 		return err, false
 	}
-	t := v.Type()
-	if t == d.lastType {
-		return d.lastValuSetter(v, rawValue), true
+	return d.decodeLine(v, setter, line, d.lineMeta(line)), true
+}
+
+// nextLine advances the decoder to the next line of input that is not skipped and
+// returns it. The returned slice is only valid until the next call to
+// nextLine. False is returned if there is no remaining data to read.
+func (d *Decoder) nextLine() (line []byte, ok bool, err error) {
+	for {
+		line, ok, err = d.scanLine()
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			d.done = true
+			return nil, false, nil
+		}
+		reason := d.skipReason(line)
+		if reason == "" {
+			break
+		}
+		d.skip(line, reason)
 	}
-	valueSetter := newValueSetter(t)
-	d.lastType = t
-	d.lastValuSetter = valueSetter
-	return valueSetter(v, rawValue), true
+	if d.maxLineLength > 0 {
+		n := len(line)
+		if d.useCodepointIndices {
+			n = utf8.RuneCount(line)
+		}
+		if n < d.minLineLength || n > d.maxLineLength {
+			return nil, false, &LineLengthError{Line: d.lineNum, Length: n, Min: d.minLineLength, Max: d.maxLineLength}
+		}
+	}
+	return line, true, nil
+}
+
+// decodeLine decodes line into v using setter. meta is the position of the line in the
+// input. line is not retained; only the values stored into string fields are copied
+// from it.
+//
+// decodeLine only reads the configuration of the decoder, so it is safe to call from
+// multiple goroutines.
+func (d *Decoder) decodeLine(v reflect.Value, setter lineSetter, line []byte, meta lineMeta) error {
+	err := setter(v, newRawBytes(line, d.useCodepointIndices), meta)
+	if e, ok := err.(*UnmarshalTypeError); ok && e.Line == 0 {
+		e.Line = meta.lineNum
+	}
+	return err
 }
 
-func rawValueFromLine(value rawValue, startPos, endPos int, format format) rawValue {
-	var trimFunc func(r rawValue) rawValue
+func rawValueFromLine(value rawBytes, startPos, endPos int, format format) rawBytes {
+	var trimFunc func(r rawBytes) rawBytes
 
 	switch format.alignment {
 	case left: // Aligned left, so trim from right side.
-		trimFunc = func(r rawValue) rawValue {
+		trimFunc = func(r rawBytes) rawBytes {
 			return r.trimRight(string(format.padChar))
 		}
 	case right: // Aligned right, so trim from left side.
-		trimFunc = func(r rawValue) rawValue {
+		trimFunc = func(r rawBytes) rawBytes {
 			return r.trimLeft(string(format.padChar))
 		}
 	case alignmentNone:
-		trimFunc = func(r rawValue) rawValue { return r }
+		trimFunc = func(r rawBytes) rawBytes { return r }
 	default:
-		trimFunc = func(r rawValue) rawValue {
+		trimFunc = func(r rawBytes) rawBytes {
 			return r.trim(string(format.padChar))
 		}
 	}
 
 	if value.codepointIndices != nil {
 		if len(value.codepointIndices) == 0 || startPos > len(value.codepointIndices) {
-			return rawValue{data: ""}
+			return rawBytes{}
 		}
 		var relevantIndices []int
-		var lineData string
+		var lineData []byte
 		if endPos >= len(value.codepointIndices) {
 			relevantIndices = value.codepointIndices[startPos-1:]
 			lineData = value.data[relevantIndices[0]:]
@@ -240,23 +507,50 @@ func rawValueFromLine(value rawValue, startPos, endPos int, format format) rawVa
 			}
 		}
 
-		return trimFunc(rawValue{data: lineData, codepointIndices: newIndices})
+		return trimFunc(rawBytes{data: lineData, codepointIndices: newIndices})
 	} else {
 		if len(value.data) == 0 || startPos > len(value.data) {
-			return rawValue{data: ""}
+			return rawBytes{}
 		}
 		if endPos > len(value.data) {
 			endPos = len(value.data)
 		}
-		return trimFunc(rawValue{data: value.data[startPos-1 : endPos]})
+		return trimFunc(rawBytes{data: value.data[startPos-1 : endPos]})
 	}
 }
 
-type valueSetter func(v reflect.Value, raw rawValue) error
+type valueSetter func(v reflect.Value, raw rawBytes) error
+
+var unmarshalerType = reflect.TypeOf(new(Unmarshaler)).Elem()
+
+// valueSetter returns the lineSetter used to decode a line into a value of type t.
+func (d *Decoder) valueSetter(t reflect.Type) lineSetter {
+	return withLineMetadata(t, d.typeSetter(t))
+}
+
+// typeSetter returns the valueSetter used to decode a line into a value of type t. It
+// prefers the Unmarshaler implementation of t, which is only valid for byte indices.
+func (d *Decoder) typeSetter(t reflect.Type) valueSetter {
+	if d.fieldFilter != nil {
+		return d.projectedSetter(t)
+	}
+	if !d.useCodepointIndices {
+		if t.Implements(unmarshalerType) {
+			return unmarshalerSetter(t, false)
+		}
+		if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(unmarshalerType) {
+			return unmarshalerSetter(t, true)
+		}
+	}
+	return newValueSetter(t)
+}
 
 var textUnmarshalerType = reflect.TypeOf(new(encoding.TextUnmarshaler)).Elem()
 
 func newValueSetter(t reflect.Type) valueSetter {
+	if t == recordPtrType {
+		return recordSetter
+	}
 	if t.Implements(textUnmarshalerType) {
 		return textUnmarshalerSetter(t, false)
 	}
@@ -288,28 +582,37 @@ func newValueSetter(t reflect.Type) valueSetter {
 }
 
 func structSetter(t reflect.Type) valueSetter {
+	return projectedStructSetter(t, nil)
+}
+
+// projectedStructSetter is like structSetter but only decodes the fields i for which
+// keep[i] is true. A nil keep decodes every field.
+func projectedStructSetter(t reflect.Type, keep []bool) valueSetter {
 	spec := cachedStructSpec(t)
-	return func(v reflect.Value, raw rawValue) error {
+	return func(v reflect.Value, raw rawBytes) error {
 		for i, fieldSpec := range spec.fieldSpecs {
-			if !fieldSpec.ok {
+			if !fieldSpec.ok || keep != nil && !keep[i] {
 				continue
 			}
 			rawValue := rawValueFromLine(raw, fieldSpec.startPos, fieldSpec.endPos, fieldSpec.format)
 			err := fieldSpec.setter(v.Field(i), rawValue)
 			if err != nil {
 				sf := t.Field(i)
-				return &UnmarshalTypeError{raw.data, sf.Type, t.Name(), sf.Name, err}
+				return &UnmarshalTypeError{Value: string(raw.data), Type: sf.Type, Struct: t.Name(), Field: sf.Name, Cause: err}
 			}
 		}
+		if spec.rawLine >= 0 {
+			v.Field(spec.rawLine).SetBytes(append([]byte(nil), raw.data...))
+		}
 		return nil
 	}
 }
 
-func unknownSetter(v reflect.Value, raw rawValue) error {
+func unknownSetter(v reflect.Value, raw rawBytes) error {
 	return errors.New("fixedwidth: unknown type")
 }
 
-func nilSetter(v reflect.Value, _ rawValue) error {
+func nilSetter(v reflect.Value, _ rawBytes) error {
 	if v.IsNil() {
 		return nil
 	}
@@ -329,7 +632,7 @@ func nilSetter(v reflect.Value, _ rawValue) error {
 }
 
 func textUnmarshalerSetter(t reflect.Type, shouldAddr bool) valueSetter {
-	return func(v reflect.Value, raw rawValue) error {
+	return func(v reflect.Value, raw rawBytes) error {
 		if shouldAddr {
 			v = v.Addr()
 		}
@@ -337,17 +640,39 @@ func textUnmarshalerSetter(t reflect.Type, shouldAddr bool) valueSetter {
 		if t.Kind() == reflect.Ptr && v.IsNil() {
 			v.Set(reflect.New(t.Elem()))
 		}
-		return v.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw.data))
+		return v.Interface().(encoding.TextUnmarshaler).UnmarshalText(raw.data)
 	}
 }
 
-func interfaceSetter(v reflect.Value, raw rawValue) error {
+func unmarshalerSetter(t reflect.Type, shouldAddr bool) valueSetter {
+	fallback := newValueSetter(t)
+	return func(v reflect.Value, raw rawBytes) error {
+		// Empty lines are decoded using reflection so that pointers are set to nil in
+		// the same way as they are for any other type.
+		if len(raw.data) == 0 {
+			return fallback(v, raw)
+		}
+		if shouldAddr {
+			v = v.Addr()
+		}
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		return v.Interface().(Unmarshaler).UnmarshalFixedWidth(raw.data)
+	}
+}
+
+func interfaceSetter(v reflect.Value, raw rawBytes) error {
 	return newValueSetter(v.Elem().Type())(v.Elem(), raw)
 }
 
 func ptrSetter(t reflect.Type) valueSetter {
-	innerSetter := newValueSetter(t.Elem())
-	return func(v reflect.Value, raw rawValue) error {
+	return ptrSetterWith(t, newValueSetter(t.Elem()))
+}
+
+// ptrSetterWith is like ptrSetter but decodes the value pointed to using innerSetter.
+func ptrSetterWith(t reflect.Type, innerSetter valueSetter) valueSetter {
+	return func(v reflect.Value, raw rawBytes) error {
 		if len(raw.data) <= 0 {
 			return nilSetter(v, raw)
 		}
@@ -358,16 +683,16 @@ func ptrSetter(t reflect.Type) valueSetter {
 	}
 }
 
-func stringSetter(v reflect.Value, raw rawValue) error {
-	v.SetString(raw.data)
+func stringSetter(v reflect.Value, raw rawBytes) error {
+	v.SetString(string(raw.data))
 	return nil
 }
 
-func intSetter(v reflect.Value, raw rawValue) error {
+func intSetter(v reflect.Value, raw rawBytes) error {
 	if len(raw.data) < 1 {
 		return nil
 	}
-	i, err := strconv.Atoi(raw.data)
+	i, err := parse.Int(raw.data)
 	if err != nil {
 		return err
 	}
@@ -376,11 +701,11 @@ func intSetter(v reflect.Value, raw rawValue) error {
 }
 
 func floatSetter(bitSize int) valueSetter {
-	return func(v reflect.Value, raw rawValue) error {
+	return func(v reflect.Value, raw rawBytes) error {
 		if len(raw.data) < 1 {
 			return nil
 		}
-		f, err := strconv.ParseFloat(raw.data, bitSize)
+		f, err := parse.Float(raw.data, bitSize)
 		if err != nil {
 			return err
 		}
@@ -389,11 +714,11 @@ func floatSetter(bitSize int) valueSetter {
 	}
 }
 
-func uintSetter(v reflect.Value, raw rawValue) error {
+func uintSetter(v reflect.Value, raw rawBytes) error {
 	if len(raw.data) < 1 {
 		return nil
 	}
-	i, err := strconv.ParseUint(raw.data, 10, 64)
+	i, err := parse.Uint(raw.data)
 	if err != nil {
 		return err
 	}
@@ -401,12 +726,12 @@ func uintSetter(v reflect.Value, raw rawValue) error {
 	return nil
 }
 
-func boolSetter(v reflect.Value, raw rawValue) error {
+func boolSetter(v reflect.Value, raw rawBytes) error {
 	if len(raw.data) == 0 {
 		return nil
 	}
 
-	val, err := strconv.ParseBool(raw.data)
+	val, err := parse.Bool(raw.data)
 	if err != nil {
 		return err
 	}
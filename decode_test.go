@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"encoding"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -326,7 +327,7 @@ func TestNewValueSetter(t *testing.T) {
 			// ensure we have an addressable target
 			var i = reflect.Indirect(reflect.New(reflect.TypeOf(tt.expected)))
 
-			err := newValueSetter(i.Type())(i, rawValue{data: string(tt.raw)})
+			err := newValueSetter(i.Type())(i, rawBytes{data: tt.raw})
 			if tt.shouldErr != (err != nil) {
 				t.Errorf("newValueSetter(%s)() err want %v, have %v (%v)", reflect.TypeOf(tt.expected).Name(), tt.shouldErr, err != nil, err.Error())
 			}
@@ -494,6 +495,30 @@ func TestDecode_EOF(t *testing.T) {
 	}
 }
 
+// Verify that decoded strings do not share memory with the Decoder's line buffer,
+// which is reused for every line.
+func TestDecode_StringsAreCopied(t *testing.T) {
+	type S struct {
+		Field1 string  `fixed:"1,3"`
+		Field2 *string `fixed:"4,6"`
+	}
+	d := NewDecoder(bytes.NewReader([]byte("foobar\nbazbiz\n")))
+
+	var first, second S
+	if err := d.Decode(&first); err != nil {
+		t.Fatalf("Unexpected error from Decode: %v", err)
+	}
+	if err := d.Decode(&second); err != nil {
+		t.Fatalf("Unexpected error from Decode: %v", err)
+	}
+	if !reflect.DeepEqual(first, S{"foo", stringp("bar")}) {
+		t.Errorf("Decode() first line changed after reading the next line: %#v", first)
+	}
+	if !reflect.DeepEqual(second, S{"baz", stringp("biz")}) {
+		t.Errorf("Unexpected result from Decode: %#v", second)
+	}
+}
+
 // Verify that lines longer than the bufio.Scanner buffer decode correctly. See
 // https://github.com/ianlopshire/go-fixedwidth/issues/47.
 func TestDecode_VeryLongLines(t *testing.T) {
@@ -505,8 +530,10 @@ func TestDecode_VeryLongLines(t *testing.T) {
 	data := bytes.Repeat([]byte("a"), bufio.MaxScanTokenSize)
 
 	d := NewDecoder(bytes.NewReader(data))
-	if err := d.Decode(&s); err != ErrTooLong {
-		t.Errorf("Decode should have returned ErrTooLong. Returned: %v", err)
+	err := d.Decode(&s)
+	var tooLong *LineTooLongError
+	if !errors.Is(err, ErrTooLong) || !errors.As(err, &tooLong) || tooLong.Line != 1 {
+		t.Errorf("Decode should have returned ErrTooLong for line 1. Returned: %v", err)
 	}
 }
 
@@ -539,6 +566,9 @@ func TestNewRawValue(t *testing.T) {
 			} else if !reflect.DeepEqual(tt.expected, result.codepointIndices) {
 				t.Errorf("newRawValue(%v, true): Unexpected result, expected %v got %v", tt.input, tt.expected, result.codepointIndices)
 			}
+			if b := newRawBytes(tt.input, true); !reflect.DeepEqual(tt.expected, b.codepointIndices) {
+				t.Errorf("newRawBytes(%v, true): Unexpected result, expected %v got %v", tt.input, tt.expected, b.codepointIndices)
+			}
 		})
 	}
 }
@@ -618,3 +648,139 @@ func TestLineSeparator(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_SetRecordLength(t *testing.T) {
+	type S struct {
+		String string `fixed:"1,3"`
+		Int    int    `fixed:"4,6"`
+	}
+	for _, tt := range []struct {
+		name                string
+		rawValue            []byte
+		useCodepointIndices bool
+		expected            []S
+		err                 error
+	}{
+		{
+			name:     "records",
+			rawValue: []byte("foo  1bar 22baz333"),
+			expected: []S{{"foo", 1}, {"bar", 22}, {"baz", 333}},
+		},
+		{
+			name:     "terminators are data",
+			rawValue: []byte("fo\n  1ba\n 22"),
+			expected: []S{{"fo\n", 1}, {"ba\n", 22}},
+		},
+		{
+			name:                "codepoints",
+			rawValue:            []byte("f☃☃  1b☃r 22"),
+			useCodepointIndices: true,
+			expected:            []S{{"f☃☃", 1}, {"b☃r", 22}},
+		},
+		{
+			name:     "short final record",
+			rawValue: []byte("foo  1bar 2"),
+			expected: []S{{"foo", 1}},
+			err:      ErrShortRecord,
+		},
+		{
+			name:                "short final record codepoints",
+			rawValue:            []byte("f☃☃  1b☃r 2"),
+			useCodepointIndices: true,
+			expected:            []S{{"f☃☃", 1}},
+			err:                 ErrShortRecord,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(bytes.NewReader(tt.rawValue))
+			dec.SetRecordLength(6)
+			dec.SetUseCodepointIndices(tt.useCodepointIndices)
+			var have []S
+			err := dec.Decode(&have)
+			if err != tt.err {
+				t.Errorf("Decode() err want %v, have %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(have, tt.expected) {
+				t.Errorf("Decode() want %+v, have %+v", tt.expected, have)
+			}
+		})
+	}
+}
+
+func TestDecoder_SetLineLengthRange(t *testing.T) {
+	type S struct {
+		String string `fixed:"1,3"`
+	}
+	for _, tt := range []struct {
+		name                string
+		rawValue            []byte
+		min, max            int
+		useCodepointIndices bool
+		expected            []S
+		err                 error
+	}{
+		{
+			name:     "exact",
+			rawValue: []byte("foo \nbar \n"),
+			min:      4, max: 4,
+			expected: []S{{"foo"}, {"bar"}},
+		},
+		{
+			name:     "too short",
+			rawValue: []byte("foo \nbar\n"),
+			min:      4, max: 4,
+			expected: []S{{"foo"}},
+			err:      &LineLengthError{Line: 2, Length: 3, Min: 4, Max: 4},
+		},
+		{
+			name:     "too long",
+			rawValue: []byte("foo  \n"),
+			min:      4, max: 4,
+			expected: nil,
+			err:      &LineLengthError{Line: 1, Length: 5, Min: 4, Max: 4},
+		},
+		{
+			name:     "range",
+			rawValue: []byte("foo\nbar  \nbaz   "),
+			min:      3, max: 5,
+			expected: []S{{"foo"}, {"bar"}},
+			err:      &LineLengthError{Line: 3, Length: 6, Min: 3, Max: 5},
+		},
+		{
+			name:     "codepoints",
+			rawValue: []byte("f☃☃ \nb☃r\n"),
+			min:      4, max: 4,
+			useCodepointIndices: true,
+			expected:            []S{{"f☃☃"}},
+			err:                 &LineLengthError{Line: 2, Length: 3, Min: 4, Max: 4},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dec := NewDecoder(bytes.NewReader(tt.rawValue))
+			dec.SetLineLengthRange(tt.min, tt.max)
+			dec.SetUseCodepointIndices(tt.useCodepointIndices)
+			var have []S
+			err := dec.Decode(&have)
+			if !reflect.DeepEqual(err, tt.err) {
+				t.Errorf("Decode() err want %v, have %v", tt.err, err)
+			}
+			if !reflect.DeepEqual(have, tt.expected) {
+				t.Errorf("Decode() want %+v, have %+v", tt.expected, have)
+			}
+		})
+	}
+}
+
+func TestLineLengthError(t *testing.T) {
+	for _, tt := range []struct {
+		err  *LineLengthError
+		want string
+	}{
+		{&LineLengthError{Line: 2, Length: 3, Min: 4, Max: 4}, "fixedwidth: line 2: length 3, want 4"},
+		{&LineLengthError{Line: 7, Length: 96, Min: 80, Max: 94}, "fixedwidth: line 7: length 96, want between 80 and 94"},
+	} {
+		if have := tt.err.Error(); have != tt.want {
+			t.Errorf("Error() want %q, have %q", tt.want, have)
+		}
+	}
+}
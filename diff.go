@@ -0,0 +1,164 @@
+package fixedwidth
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Kinds of RecordDiff.
+const (
+	DiffAdded   = "added"
+	DiffRemoved = "removed"
+	DiffChanged = "changed"
+)
+
+// A RecordDiff describes how the record with a given key differs between two inputs.
+type RecordDiff struct {
+	// Kind is one of DiffAdded, DiffRemoved, or DiffChanged.
+	Kind string
+
+	// Key holds the values of the key fields.
+	Key []string
+
+	// Old and New are the records in the old and new input. Old is nil for an added
+	// record and New is nil for a removed record. OldLine and NewLine are the lines
+	// the records were read from, or 0 if there is no record.
+	Old, New         *Record
+	OldLine, NewLine int
+
+	// Fields holds the fields that differ, in layout order, if Kind is DiffChanged.
+	Fields []FieldDiff
+}
+
+// A FieldDiff describes a field whose value differs between two records.
+type FieldDiff struct {
+	Name     string
+	Old, New string
+}
+
+// Diff decodes the remaining records from old and new using layout, matches them by the
+// values of the named key fields, and returns their differences. Records are compared
+// field by field using their decoded values, so differences in padding alone are not
+// reported.
+//
+// Added and changed records are returned in the order of the new input, followed by the
+// removed records in the order of the old input. The old input is held in memory. An
+// error is returned if a key occurs more than once in either input.
+func Diff(old, new *Decoder, layout *Layout, keys ...string) ([]RecordDiff, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("fixedwidth: Diff requires at least one key field")
+	}
+	keyIndices := make([]int, len(keys))
+	for i, name := range keys {
+		if keyIndices[i] = layout.Index(name); keyIndices[i] < 0 {
+			return nil, errors.New("fixedwidth: unknown layout field " + name)
+		}
+	}
+
+	type oldRecord struct {
+		rec     *Record
+		line    int
+		matched bool
+	}
+	var (
+		oldRecords = make(map[string]*oldRecord)
+		oldOrder   []string
+	)
+	err := readRecords(old, layout, func(rec *Record, line int) error {
+		k := diffKey(rec, keyIndices)
+		if _, ok := oldRecords[k]; ok {
+			return duplicateKeyError(line)
+		}
+		oldRecords[k] = &oldRecord{rec: rec, line: line}
+		oldOrder = append(oldOrder, k)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		diffs   []RecordDiff
+		newSeen = make(map[string]bool)
+	)
+	err = readRecords(new, layout, func(rec *Record, line int) error {
+		k := diffKey(rec, keyIndices)
+		if newSeen[k] {
+			return duplicateKeyError(line)
+		}
+		newSeen[k] = true
+
+		o, ok := oldRecords[k]
+		if !ok {
+			diffs = append(diffs, RecordDiff{Kind: DiffAdded, Key: keyValues(rec, keyIndices), New: rec, NewLine: line})
+			return nil
+		}
+		o.matched = true
+
+		var fields []FieldDiff
+		for i, f := range layout.Fields {
+			if o.rec.Values[i] != rec.Values[i] {
+				fields = append(fields, FieldDiff{Name: f.Name, Old: o.rec.Values[i], New: rec.Values[i]})
+			}
+		}
+		if len(fields) > 0 {
+			diffs = append(diffs, RecordDiff{
+				Kind:    DiffChanged,
+				Key:     keyValues(rec, keyIndices),
+				Old:     o.rec,
+				New:     rec,
+				OldLine: o.line,
+				NewLine: line,
+				Fields:  fields,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, k := range oldOrder {
+		if o := oldRecords[k]; !o.matched {
+			diffs = append(diffs, RecordDiff{Kind: DiffRemoved, Key: keyValues(o.rec, keyIndices), Old: o.rec, OldLine: o.line})
+		}
+	}
+	return diffs, nil
+}
+
+// readRecords decodes the remaining records from d using layout and calls fn with each
+// of them and the line it was read from.
+func readRecords(d *Decoder, layout *Layout, fn func(rec *Record, line int) error) error {
+	for {
+		rec := layout.NewRecord()
+		err := d.Decode(rec)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(rec, d.lineNum); err != nil {
+			return err
+		}
+	}
+}
+
+func keyValues(rec *Record, keyIndices []int) []string {
+	values := make([]string, len(keyIndices))
+	for i, j := range keyIndices {
+		values[i] = rec.Values[j]
+	}
+	return values
+}
+
+// diffKey returns a string that uniquely identifies the key values of rec.
+func diffKey(rec *Record, keyIndices []int) string {
+	return strings.Join(keyValues(rec, keyIndices), "\x00")
+}
+
+func duplicateKeyError(line int) error {
+	return errors.New("fixedwidth: line " + strconv.Itoa(line) + ": duplicate key")
+}
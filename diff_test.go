@@ -0,0 +1,99 @@
+package fixedwidth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	layout := &Layout{Fields: []LayoutField{
+		{Name: "ID", Start: 1, End: 3, Alignment: "right", Pad: "0"},
+		{Name: "Name", Start: 4, End: 9},
+		{Name: "Grade", Start: 10, End: 13, Alignment: "right"},
+	}}
+	record := func(values ...string) *Record {
+		return &Record{Layout: layout, Values: values}
+	}
+
+	old := "" +
+		"001Ian   99.5\n" +
+		"002Jane  79.5\n" +
+		"003Ann   89.5\n" +
+		"004John  70.0\n"
+	new := "" +
+		"004John  70.0\n" +
+		"003Ann   90.0\n" +
+		"005Bob   60.0\n" +
+		"01 Ian   99.5\n"
+
+	have, err := Diff(NewDecoder(strings.NewReader(old)), NewDecoder(strings.NewReader(new)), layout, "ID")
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	want := []RecordDiff{
+		{
+			Kind:    DiffChanged,
+			Key:     []string{"3"},
+			Old:     record("3", "Ann", "89.5"),
+			New:     record("3", "Ann", "90.0"),
+			OldLine: 3,
+			NewLine: 2,
+			Fields:  []FieldDiff{{Name: "Grade", Old: "89.5", New: "90.0"}},
+		},
+		{Kind: DiffAdded, Key: []string{"5"}, New: record("5", "Bob", "60.0"), NewLine: 3},
+		{Kind: DiffAdded, Key: []string{"1 "}, New: record("1 ", "Ian", "99.5"), NewLine: 4},
+		{Kind: DiffRemoved, Key: []string{"1"}, Old: record("1", "Ian", "99.5"), OldLine: 1},
+		{Kind: DiffRemoved, Key: []string{"2"}, Old: record("2", "Jane", "79.5"), OldLine: 2},
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Diff() want\n%+v\nhave\n%+v", want, have)
+	}
+}
+
+func TestDiff_MultipleKeys(t *testing.T) {
+	layout := &Layout{Fields: []LayoutField{
+		{Name: "State", Start: 1, End: 2},
+		{Name: "Code", Start: 3, End: 5},
+		{Name: "Name", Start: 6, End: 10},
+	}}
+
+	old := "NY001Alpha\nNJ001Beta \n"
+	new := "NJ001Gamma\nNY001Alpha\n"
+	have, err := Diff(NewDecoder(strings.NewReader(old)), NewDecoder(strings.NewReader(new)), layout, "State", "Code")
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	if len(have) != 1 || have[0].Kind != DiffChanged || !reflect.DeepEqual(have[0].Key, []string{"NJ", "001"}) {
+		t.Fatalf("Diff() want one change for key [NJ 001], have %+v", have)
+	}
+	if want := []FieldDiff{{Name: "Name", Old: "Beta", New: "Gamma"}}; !reflect.DeepEqual(have[0].Fields, want) {
+		t.Errorf("Diff() fields want %+v, have %+v", want, have[0].Fields)
+	}
+}
+
+func TestDiff_Errors(t *testing.T) {
+	layout := &Layout{Fields: []LayoutField{
+		{Name: "ID", Start: 1, End: 1},
+		{Name: "Name", Start: 2, End: 5},
+	}}
+
+	for _, tt := range []struct {
+		name     string
+		old, new string
+		keys     []string
+		want     string
+	}{
+		{name: "no keys", want: "fixedwidth: Diff requires at least one key field"},
+		{name: "unknown key", keys: []string{"Missing"}, want: "fixedwidth: unknown layout field Missing"},
+		{name: "duplicate in old", old: "1a\n2b\n1c\n", keys: []string{"ID"}, want: "fixedwidth: line 3: duplicate key"},
+		{name: "duplicate in new", new: "1a\n1b\n", keys: []string{"ID"}, want: "fixedwidth: line 2: duplicate key"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Diff(NewDecoder(strings.NewReader(tt.old)), NewDecoder(strings.NewReader(tt.new)), layout, tt.keys...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("Diff() want error %q, have %v", tt.want, err)
+			}
+		})
+	}
+}
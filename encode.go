@@ -3,11 +3,13 @@ package fixedwidth
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding"
 	"io"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Marshal returns the fixed-width encoding of v.
@@ -56,6 +58,16 @@ func (e *MarshalInvalidTypeError) Error() string {
 	return "fixedwidth: cannot marshal unknown Type " + e.typeName
 }
 
+// Marshaler is the interface implemented by types that can marshal themselves into a
+// fixed-width line. The line must not include a line terminator.
+//
+// Unless codepoint indices are in use, the Encoder calls MarshalFixedWidth instead of
+// encoding the fields of a struct using reflection. The fixedwidth-gen command
+// generates implementations of Marshaler.
+type Marshaler interface {
+	MarshalFixedWidth() ([]byte, error)
+}
+
 // An Encoder writes fixed-width formatted data to an output
 // stream.
 type Encoder struct {
@@ -64,6 +76,23 @@ type Encoder struct {
 
 	useCodepointIndices bool
 
+	// recordLength is the length of each record when records of a fixed length are
+	// written without line terminators, 0 otherwise.
+	recordLength int
+
+	// lineLength is the length every line is padded to, 0 if lines are not padded.
+	lineLength int
+
+	// useTerminatorFields is set by SetUseLineTerminatorFields.
+	useTerminatorFields bool
+
+	// writeBOM is set by SetWriteBOM. wroteBOM is set once the byte order mark has been
+	// written.
+	writeBOM, wroteBOM bool
+
+	// ctx is the context passed to EncodeContext while it runs, nil otherwise.
+	ctx context.Context
+
 	lastType         reflect.Type
 	lastValueEncoder valueEncoder
 }
@@ -83,11 +112,39 @@ func (e *Encoder) SetLineTerminator(lineTerminator []byte) {
 	e.lineTerminator = lineTerminator
 }
 
+// SetUseLineTerminatorFields configures the Encoder to write the value of the
+// LineTerminator field of a struct after its line, so that values decoded with their
+// terminators are written with the same line endings. A struct whose LineTerminator is
+// nil, or that has no such field, is followed by the line terminator of the Encoder
+// unless it is the last value written by a call to Encode, as by default. A Writer
+// writes the terminators in the same way.
+func (e *Encoder) SetUseLineTerminatorFields(use bool) {
+	e.useTerminatorFields = use
+}
+
+// SetLineLength configures the Encoder to pad every line with spaces to a length of n
+// bytes, or n codepoints if codepoint indices are in use. A line with data past n gives
+// a *LineLengthError; only spaces, such as the padding of fields past n, are dropped.
+// By default, a line ends after the last field. A value of n less than 1 restores the
+// default behavior.
+func (e *Encoder) SetLineLength(n int) {
+	e.lineLength = n
+}
+
+// SetRecordLength configures the Encoder to write records of exactly n bytes, or n
+// codepoints if codepoint indices are in use, without line terminators. Shorter records
+// are padded with spaces, and longer records are handled as by SetLineLength. A value
+// of n less than 1 restores the default behavior of writing terminated lines.
+func (e *Encoder) SetRecordLength(n int) {
+	e.recordLength = n
+}
+
 // SetUseCodepointIndices configures `Encoder` on whether the indices in the
 // `fixedwidth` struct tags are expressed in terms of bytes (the default
 // behavior) or in terms of UTF-8 decoded codepoints.
 func (e *Encoder) SetUseCodepointIndices(use bool) {
 	e.useCodepointIndices = use
+	e.lastType = nil
 }
 
 // Encode writes the fixed-width encoding of v to the
@@ -109,7 +166,10 @@ func (e *Encoder) Encode(i interface{}) (err error) {
 		err = e.writeLines(v)
 	} else {
 		// this is a single object so encode the original vale to a line
-		err = e.writeLine(reflect.ValueOf(i))
+		if err = e.writeLine(reflect.ValueOf(i)); err == nil {
+			err = e.writeTerminatorField(v, false)
+		}
+		setLineLengthErrorLine(err, 1)
 	}
 	if err != nil {
 		return err
@@ -119,44 +179,151 @@ func (e *Encoder) Encode(i interface{}) (err error) {
 
 func (e *Encoder) writeLines(v reflect.Value) error {
 	for i := 0; i < v.Len(); i++ {
+		if err := e.checkContext(i); err != nil {
+			return err
+		}
 		err := e.writeLine(v.Index(i))
 		if err != nil {
+			setLineLengthErrorLine(err, i+1)
 			return err
 		}
 
-		if i != v.Len()-1 {
-			_, err := e.w.Write(e.lineTerminator)
-			if err != nil {
-				return err
-			}
+		if err := e.writeTerminatorField(v.Index(i), i != v.Len()-1); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// writeTerminatorField writes the terminator after the line of v. That is the value of
+// its LineTerminator field if the Encoder uses those and it is set, or the line
+// terminator of the Encoder if more lines follow.
+func (e *Encoder) writeTerminatorField(v reflect.Value, more bool) error {
+	if term := e.terminatorField(v); term != nil {
+		_, err := e.w.Write(term)
+		return err
+	}
+	if more {
+		return e.writeTerminator()
+	}
+	return nil
+}
+
+// terminatorField returns the value of the LineTerminator field of v if the Encoder uses
+// those, or nil.
+func (e *Encoder) terminatorField(v reflect.Value) []byte {
+	if !e.useTerminatorFields {
+		return nil
+	}
+	return lineTerminatorField(v)
+}
+
+// writeTerminator writes the separator between two lines, which is empty when a record
+// length is set.
+func (e *Encoder) writeTerminator() error {
+	if e.recordLength > 0 {
+		return nil
+	}
+	_, err := e.w.Write(e.lineTerminator)
+	return err
+}
+
 func (e *Encoder) writeLine(v reflect.Value) (err error) {
 	t := v.Type()
-	encoder := e.lastValueEncoder
 	if e.lastType != t {
 		e.lastType = t
-		e.lastValueEncoder = newValueEncoder(t, e.useCodepointIndices)
-		encoder = e.lastValueEncoder
+		e.lastValueEncoder = e.valueEncoder(t)
 	}
+	return e.writeLineWith(v, e.lastValueEncoder)
+}
 
+// writeLineWith is like writeLine but encodes the line using the provided valueEncoder.
+func (e *Encoder) writeLineWith(v reflect.Value, encoder valueEncoder) error {
 	b, err := encoder(v)
 	if err != nil {
 		return err
 	}
+	if e.writeBOM && !e.wroteBOM {
+		if _, err := e.w.Write(utf8BOM); err != nil {
+			return err
+		}
+		e.wroteBOM = true
+	}
+	if e.recordLength > 0 {
+		return e.writeFitted(b, e.recordLength)
+	}
+	if e.lineLength > 0 {
+		return e.writeFitted(b, e.lineLength)
+	}
 	_, err = e.w.WriteString(b.data)
 	return err
 }
 
+// writeFitted writes b padded with spaces to a length of n. Spaces past n are dropped,
+// and any other data past n gives a *LineLengthError.
+func (e *Encoder) writeFitted(b rawValue, n int) (err error) {
+	if b.len() > n {
+		if rest := b.data[b.byteStartIndex(n):]; strings.TrimRight(rest, " ") != "" {
+			data := strings.TrimRight(b.data, " ")
+			length := len(data)
+			if b.hasMultiByteChar() {
+				length = utf8.RuneCountInString(data)
+			}
+			return &LineLengthError{Length: length, Min: n, Max: n}
+		}
+		if b, err = b.slice(0, n-1); err != nil {
+			return err
+		}
+	}
+	if _, err := e.w.WriteString(b.data); err != nil {
+		return err
+	}
+	for i := b.len(); i < n; i++ {
+		if err := e.w.WriteByte(' '); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setLineLengthErrorLine sets the line of err to line if it is a *LineLengthError.
+func setLineLengthErrorLine(err error, line int) {
+	if e, ok := err.(*LineLengthError); ok {
+		e.Line = line
+	}
+}
+
 type valueEncoder func(v reflect.Value) (rawValue, error)
 
+var marshalerType = reflect.TypeOf(new(Marshaler)).Elem()
+
+// valueEncoder returns the valueEncoder used to encode a value of type t as a line. It
+// prefers the Marshaler implementation of t, which is only valid for byte indices.
+func (e *Encoder) valueEncoder(t reflect.Type) valueEncoder {
+	if t != nil && !e.useCodepointIndices && t.Implements(marshalerType) {
+		return marshalerEncoder
+	}
+	return newValueEncoder(t, e.useCodepointIndices)
+}
+
+func marshalerEncoder(v reflect.Value) (rawValue, error) {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		return nilEncoder(v)
+	}
+	b, err := v.Interface().(Marshaler).MarshalFixedWidth()
+	if err != nil {
+		return rawValue{}, err
+	}
+	return rawValue{data: string(b)}, nil
+}
+
 func newValueEncoder(t reflect.Type, useCodepointIndices bool) valueEncoder {
 	if t == nil {
 		return nilEncoder
 	}
+	if t == recordType {
+		return recordEncoder(useCodepointIndices)
+	}
 	if t.Implements(reflect.TypeOf(new(encoding.TextMarshaler)).Elem()) {
 		return textMarshalerEncoder(useCodepointIndices)
 	}
@@ -233,7 +400,15 @@ func structEncoder(useCodepointIndices bool) valueEncoder {
 		if useCodepointIndices {
 			c = int(1.1*float64(ss.ll)) + 1
 		}
-		b := newLineBuilder(ss.ll, c, ' ')
+		var b *lineBuilder
+		if raw := rawLineOf(v, ss); len(raw) > 0 {
+			var err error
+			if b, err = lineBuilderFromRawLine(raw, ss, useCodepointIndices); err != nil {
+				return rawValue{}, err
+			}
+		} else {
+			b = newLineBuilder(ss.ll, c, ' ')
+		}
 
 		for i, spec := range ss.fieldSpecs {
 			if !spec.ok {
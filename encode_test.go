@@ -349,3 +349,97 @@ func TestEncoder_SetLineTerminator(t *testing.T) {
 		t.Errorf("Encode() expected %q, have %q", expected, buff.Bytes())
 	}
 }
+
+func TestEncoder_SetRecordLength(t *testing.T) {
+	type S struct {
+		String string `fixed:"1,3"`
+		Int    int    `fixed:"4,6"`
+	}
+	for _, tt := range []struct {
+		name                string
+		length              int
+		useCodepointIndices bool
+		input               []S
+		expected            []byte
+	}{
+		{"padded", 8, false, []S{{"foo", 1}, {"bar", 22}}, []byte("foo1    bar22   ")},
+		{"trailing spaces dropped", 4, false, []S{{"foo", 1}, {"bar", 2}}, []byte("foo1bar2")},
+		{"codepoints", 8, true, []S{{"f☃☃", 1}, {"b☃r", 22}}, []byte("f☃☃1    b☃r22   ")},
+		{"codepoints trailing spaces dropped", 4, true, []S{{"f☃☃", 1}, {"b☃r", 2}}, []byte("f☃☃1b☃r2")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buff := new(bytes.Buffer)
+			enc := NewEncoder(buff)
+			enc.SetRecordLength(tt.length)
+			enc.SetUseCodepointIndices(tt.useCodepointIndices)
+			if err := enc.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if !bytes.Equal(tt.expected, buff.Bytes()) {
+				t.Errorf("Encode() expected %q, have %q", tt.expected, buff.Bytes())
+			}
+		})
+	}
+}
+
+func TestEncoder_SetLineLength(t *testing.T) {
+	type S struct {
+		String string `fixed:"1,3"`
+		Int    int    `fixed:"4,6"`
+	}
+	for _, tt := range []struct {
+		name                string
+		length              int
+		useCodepointIndices bool
+		input               []S
+		expected            []byte
+	}{
+		{"padded", 8, false, []S{{"foo", 1}, {"bar", 22}}, []byte("foo1    \nbar22   ")},
+		{"trailing spaces dropped", 4, false, []S{{"foo", 1}, {"bar", 2}}, []byte("foo1\nbar2")},
+		{"codepoints", 8, true, []S{{"f☃☃", 1}, {"b☃r", 22}}, []byte("f☃☃1    \nb☃r22   ")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buff := new(bytes.Buffer)
+			enc := NewEncoder(buff)
+			enc.SetLineLength(tt.length)
+			enc.SetUseCodepointIndices(tt.useCodepointIndices)
+			if err := enc.Encode(tt.input); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if !bytes.Equal(tt.expected, buff.Bytes()) {
+				t.Errorf("Encode() expected %q, have %q", tt.expected, buff.Bytes())
+			}
+		})
+	}
+}
+
+func TestEncoder_lineTooLong(t *testing.T) {
+	type S struct {
+		String string `fixed:"1,3"`
+		Int    int    `fixed:"4,6"`
+	}
+	for _, tt := range []struct {
+		name                string
+		setup               func(e *Encoder)
+		useCodepointIndices bool
+		input               interface{}
+		want                LineLengthError
+	}{
+		{"line length", func(e *Encoder) { e.SetLineLength(4) }, false, []S{{"foo", 1}, {"bar", 22}}, LineLengthError{Line: 2, Length: 5, Min: 4, Max: 4}},
+		{"record length", func(e *Encoder) { e.SetRecordLength(4) }, false, []S{{"foo", 100}}, LineLengthError{Line: 1, Length: 6, Min: 4, Max: 4}},
+		{"single value", func(e *Encoder) { e.SetLineLength(5) }, false, S{"foo", 123}, LineLengthError{Line: 1, Length: 6, Min: 5, Max: 5}},
+		{"codepoints", func(e *Encoder) { e.SetLineLength(4) }, true, []S{{"f☃☃", 1}, {"b☃r", 22}}, LineLengthError{Line: 2, Length: 5, Min: 4, Max: 4}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buff := new(bytes.Buffer)
+			enc := NewEncoder(buff)
+			tt.setup(enc)
+			enc.SetUseCodepointIndices(tt.useCodepointIndices)
+			err := enc.Encode(tt.input)
+			var lineErr *LineLengthError
+			if !errors.As(err, &lineErr) || *lineErr != tt.want {
+				t.Errorf("Encode() want error %v, have %v", &tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package fixedwidth
+
+// SetLineFilter configures the Decoder to skip the lines for which keep returns false.
+// Skipped lines are dropped before they are decoded or appended to a slice, but are
+// still counted in the line numbers reported by errors. keep is called with each line,
+// excluding its terminator, which is only valid during the call and must not be
+// modified.
+//
+// Lines are filtered before their length is checked against SetLineLengthRange. A nil
+// keep restores the default behavior of decoding every line.
+func (d *Decoder) SetLineFilter(keep func(line []byte) bool) {
+	d.lineFilter = keep
+}
+
+// SetRecordFilter is like SetLineFilter but calls keep with a RawRecord view of each
+// line, whose fields are described by the struct tags of schema, as for DecodeRaw.
+// Only the fields accessed by keep are parsed. The RawRecord is only valid during the
+// call and must not be retained.
+//
+// An error is returned if schema is not a struct or a pointer to one. A nil keep
+// restores the default behavior of decoding every line.
+func (d *Decoder) SetRecordFilter(schema interface{}, keep func(r *RawRecord) bool) error {
+	if keep == nil {
+		d.lineFilter = nil
+		return nil
+	}
+	t, err := schemaType(schema)
+	if err != nil {
+		return err
+	}
+	r := &RawRecord{d: d, t: t, ss: cachedStructSpec(t)}
+	d.lineFilter = func(line []byte) bool {
+		r.line = newRawBytes(line, d.useCodepointIndices)
+		r.meta = d.lineMeta(line)
+		return keep(r)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package fixedwidth
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_SetLineFilter(t *testing.T) {
+	type S struct {
+		Kind  string `fixed:"1,1"`
+		Value int    `fixed:"2,4"`
+	}
+
+	const input = "H  x\nD  1\nD  2\nT  y\nD  3\n"
+
+	for _, tt := range []struct {
+		name        string
+		setup       func(d *Decoder)
+		concurrency int
+		want        []S
+	}{
+		{
+			name:  "line",
+			setup: func(d *Decoder) { d.SetLineFilter(func(line []byte) bool { return line[0] == 'D' }) },
+			want:  []S{{"D", 1}, {"D", 2}, {"D", 3}},
+		},
+		{
+			name:        "line concurrent",
+			setup:       func(d *Decoder) { d.SetLineFilter(func(line []byte) bool { return line[0] == 'D' }) },
+			concurrency: 2,
+			want:        []S{{"D", 1}, {"D", 2}, {"D", 3}},
+		},
+		{
+			name: "record",
+			setup: func(d *Decoder) {
+				err := d.SetRecordFilter(S{}, func(r *RawRecord) bool {
+					kind, _ := r.String("Kind")
+					if kind != "D" {
+						return false
+					}
+					v, err := r.Int("Value")
+					return err == nil && v != 2
+				})
+				if err != nil {
+					t.Fatalf("SetRecordFilter() unexpected error: %v", err)
+				}
+			},
+			want: []S{{"D", 1}, {"D", 3}},
+		},
+		{
+			name: "nil filter",
+			setup: func(d *Decoder) {
+				d.SetLineFilter(func(line []byte) bool { return false })
+				d.SetLineFilter(nil)
+				d.SetLineFilter(func(line []byte) bool { return line[0] != 'H' && line[0] != 'T' })
+			},
+			want: []S{{"D", 1}, {"D", 2}, {"D", 3}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(input))
+			d.SetConcurrency(tt.concurrency)
+			tt.setup(d)
+			var have []S
+			if err := d.Decode(&have); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(have, tt.want) {
+				t.Errorf("Decode() want %+v, have %+v", tt.want, have)
+			}
+		})
+	}
+
+	t.Run("line numbers count skipped lines concurrently", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(strings.Repeat("H  x\nD  1\n", 300) + "D  y\n"))
+		d.SetConcurrency(2)
+		d.SetLineFilter(func(line []byte) bool { return line[0] == 'D' })
+		var have []S
+		err := d.Decode(&have)
+		var typeErr *UnmarshalTypeError
+		if !errors.As(err, &typeErr) || typeErr.Line != 601 {
+			t.Fatalf("Decode() want error on line 601, have %v", err)
+		}
+		if len(have) != 300 {
+			t.Errorf("Decode() want 300 values, have %d", len(have))
+		}
+	})
+
+	t.Run("line numbers count skipped lines", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("D  1\nH  x\nD  y\n"))
+		var lines []int
+		if err := d.SetRecordFilter((*S)(nil), func(r *RawRecord) bool {
+			lines = append(lines, r.Line())
+			return string(r.Bytes()) != "H  x"
+		}); err != nil {
+			t.Fatalf("SetRecordFilter() unexpected error: %v", err)
+		}
+		var have []S
+		err := d.Decode(&have)
+		var typeErr *UnmarshalTypeError
+		if !errors.As(err, &typeErr) || typeErr.Line != 3 {
+			t.Fatalf("Decode() want error on line 3, have %v", err)
+		}
+		if want := []int{1, 2, 3}; !reflect.DeepEqual(lines, want) {
+			t.Errorf("filter lines want %v, have %v", want, lines)
+		}
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		if err := NewDecoder(strings.NewReader("")).SetRecordFilter("", func(*RawRecord) bool { return true }); err == nil {
+			t.Errorf("SetRecordFilter() want error for non-struct schema")
+		}
+	})
+}
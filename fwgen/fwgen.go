@@ -0,0 +1,139 @@
+// Package fwgen contains helpers used by code generated by fixedwidth-gen. It is not
+// intended to be used directly.
+//
+// The helpers mirror the byte index behavior of the reflection based Encoder and
+// Decoder in package fixedwidth, so generated code produces the same output.
+package fwgen
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/ianlopshire/go-fixedwidth"
+	"github.com/ianlopshire/go-fixedwidth/internal/parse"
+)
+
+// Alignments of a field, as set by the `fixed` struct tag.
+const (
+	AlignDefault = iota
+	AlignLeft
+	AlignRight
+	AlignNone
+)
+
+// Field returns the value of the field between the 1-based inclusive positions start
+// and end of line, trimmed of pad according to alignment. The result aliases line.
+func Field(line []byte, start, end, alignment int, pad byte) []byte {
+	if len(line) == 0 || start > len(line) {
+		return nil
+	}
+	if end > len(line) {
+		end = len(line)
+	}
+	b := line[start-1 : end]
+
+	cutset := string(rune(pad))
+	switch alignment {
+	case AlignLeft:
+		return bytes.TrimRight(b, cutset)
+	case AlignRight:
+		return bytes.TrimLeft(b, cutset)
+	case AlignNone:
+		return b
+	default:
+		return bytes.Trim(b, cutset)
+	}
+}
+
+// NewLine returns a line of length n filled with spaces.
+func NewLine(n int) []byte {
+	return bytes.Repeat([]byte{' '}, n)
+}
+
+// NewLineFrom returns a copy of raw padded with spaces to at least length n.
+func NewLineFrom(raw []byte, n int) []byte {
+	if len(raw) >= n {
+		return append([]byte(nil), raw...)
+	}
+	line := make([]byte, n)
+	fill(line[copy(line, raw):], ' ')
+	return line
+}
+
+// Blank fills the field between the 1-based inclusive positions start and end of line
+// with spaces.
+func Blank(line []byte, start, end int) {
+	fill(line[start-1:end], ' ')
+}
+
+// Put writes value to the field between the 1-based inclusive positions start and end
+// of line, padding it with pad according to alignment. Values longer than the field
+// are truncated.
+func Put(line []byte, start, end, alignment int, pad byte, value []byte) {
+	dst := line[start-1 : end]
+	copy(dst[padField(dst, len(value), alignment, pad):], value)
+}
+
+// PutString is like Put but takes the value as a string.
+func PutString(line []byte, start, end, alignment int, pad byte, value string) {
+	dst := line[start-1 : end]
+	copy(dst[padField(dst, len(value), alignment, pad):], value)
+}
+
+// padField fills the part of dst not taken by a value of length n with pad and returns
+// the offset the value should be written at.
+func padField(dst []byte, n, alignment int, pad byte) int {
+	if n >= len(dst) {
+		return 0
+	}
+	switch {
+	case alignment == AlignRight:
+		fill(dst[:len(dst)-n], pad)
+		return len(dst) - n
+
+	// Values that use the default alignment with a space as the pad character are
+	// written without padding to match the behavior of the Encoder.
+	case alignment == AlignLeft, alignment == AlignDefault && pad != ' ':
+		fill(dst[n:], pad)
+	}
+	return 0
+}
+
+func fill(b []byte, c byte) {
+	for i := range b {
+		b[i] = c
+	}
+}
+
+// ParseInt parses b as a base 10 int in the same way as strconv.Atoi.
+func ParseInt(b []byte) (int, error) {
+	return parse.Int(b)
+}
+
+// ParseUint parses b as a base 10 uint64 in the same way as strconv.ParseUint.
+func ParseUint(b []byte) (uint64, error) {
+	return parse.Uint(b)
+}
+
+// ParseFloat parses b as a floating-point number in the same way as
+// strconv.ParseFloat.
+func ParseFloat(b []byte, bitSize int) (float64, error) {
+	return parse.Float(b, bitSize)
+}
+
+// ParseBool parses b as a boolean in the same way as strconv.ParseBool.
+func ParseBool(b []byte) (bool, error) {
+	return parse.Bool(b)
+}
+
+// TypeError returns the error reported when the value of a field of a struct cannot
+// be decoded. field must be a pointer to the field.
+func TypeError(line []byte, field interface{}, structName, fieldName string, cause error) error {
+	return &fixedwidth.UnmarshalTypeError{
+		Value:  string(line),
+		Type:   reflect.TypeOf(field).Elem(),
+		Struct: structName,
+		Field:  fieldName,
+		Cause:  cause,
+	}
+}
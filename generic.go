@@ -0,0 +1,181 @@
+//go:build go1.18
+// +build go1.18
+
+package fixedwidth
+
+import (
+	"io"
+	"reflect"
+)
+
+// A Reader reads values of type T from fixed-width data, one line at a time.
+//
+// Reader is a typed alternative to Decoder.Decode. The lineSetter for T is built once
+// instead of being looked up for every line, and values are always decoded into a T,
+// so there is no need to pass a pointer.
+type Reader[T any] struct {
+	d      *Decoder
+	setter lineSetter
+}
+
+// NewReader returns a new Reader that reads from d. The Decoder should be configured
+// before the first call to Read.
+func NewReader[T any](d *Decoder) *Reader[T] {
+	return &Reader[T]{d: d}
+}
+
+// Read reads the next line from the input and returns its decoded value. If there is
+// no data remaining in the input, Read returns io.EOF.
+//
+// Read behaves like Decoder.Decode called with a pointer to a T, except that the zero
+// value of T is returned along with any error rather than a partly decoded value.
+func (r *Reader[T]) Read() (T, error) {
+	if r.setter == nil {
+		r.setter = r.d.valueSetter(reflect.TypeOf((*T)(nil)))
+	}
+	var v T
+	err, ok := r.d.readLineWith(reflect.ValueOf(&v), r.setter)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !ok {
+		return v, io.EOF
+	}
+	return v, nil
+}
+
+// All returns an iterator over the remaining values in the input. If an error occurs
+// it is yielded along with the zero value of T and iteration stops. io.EOF is not
+// yielded.
+//
+// With Go 1.23 or later, All can be used in a range loop:
+//
+//	for v, err := range r.All() {
+//		...
+//	}
+func (r *Reader[T]) All() func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Each decodes the remaining values in the input and calls fn with each of them in
+// input order. If the Decoder has been configured with SetConcurrency, lines are
+// decoded by multiple goroutines while fn is always called from the calling
+// goroutine.
+//
+// Each stops at the first error, either from decoding or returned by fn, and returns
+// it. Unlike Read, Each decodes every line as an element of a slice would be decoded
+// by Decoder.Decode.
+func (r *Reader[T]) Each(fn func(T) error) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if r.d.concurrency > 1 {
+		return r.d.decodeParallel(t, func(v reflect.Value) error {
+			var x T
+			reflect.ValueOf(&x).Elem().Set(v)
+			return fn(x)
+		})
+	}
+
+	setter := r.d.valueSetter(t)
+	for {
+		var v T
+		err, ok := r.d.readLineWith(reflect.ValueOf(&v).Elem(), setter)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+}
+
+// Records returns an iterator over the records of r with indices in [start, end),
+// decoded as values of type T. If an error occurs it is yielded along with the zero
+// value of T and iteration stops.
+//
+// With Go 1.23 or later, Records can be used in a range loop:
+//
+//	for v, err := range fixedwidth.Records[Person](r, 0, r.Len()) {
+//		...
+//	}
+func Records[T any](r *RecordReader, start, end int) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		setter := r.d.valueSetter(reflect.TypeOf((*T)(nil)).Elem())
+		for i := start; i < end; i++ {
+			var v T
+			if err := r.readRecordWith(i, reflect.ValueOf(&v).Elem(), setter); err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// A Writer writes values of type T as fixed-width data, one line per value.
+//
+// Writer is a typed alternative to Encoder.Encode. The valueEncoder for T is built
+// once instead of being looked up for every value. Lines are separated in the same way
+// as when a slice is encoded.
+type Writer[T any] struct {
+	e       *Encoder
+	encoder valueEncoder
+	n       int
+
+	// separate is set if the line terminator of the Encoder is to be written before
+	// the next line.
+	separate bool
+}
+
+// NewWriter returns a new Writer that writes to e. The Encoder should be configured
+// before the first call to Write.
+func NewWriter[T any](e *Encoder) *Writer[T] {
+	return &Writer[T]{e: e}
+}
+
+// Write writes the fixed-width encoding of v. Output is buffered; Flush must be
+// called once all values have been written.
+func (w *Writer[T]) Write(v T) error {
+	if w.encoder == nil {
+		w.encoder = w.e.valueEncoder(reflect.TypeOf((*T)(nil)).Elem())
+	}
+	if w.separate {
+		if err := w.e.writeTerminator(); err != nil {
+			return err
+		}
+	}
+	w.n++
+	rv := reflect.ValueOf(&v).Elem()
+	if err := w.e.writeLineWith(rv, w.encoder); err != nil {
+		setLineLengthErrorLine(err, w.n)
+		return err
+	}
+	w.separate = true
+	if term := w.e.terminatorField(rv); term != nil {
+		w.separate = false
+		_, err := w.e.w.Write(term)
+		return err
+	}
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer.
+func (w *Writer[T]) Flush() error {
+	return w.e.w.Flush()
+}
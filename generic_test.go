@@ -0,0 +1,264 @@
+//go:build go1.18
+// +build go1.18
+
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestReader(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,3"`
+		B int    `fixed:"4,6"`
+	}
+
+	t.Run("Read", func(t *testing.T) {
+		r := NewReader[S](NewDecoder(strings.NewReader("foo  1\n\nbar  2")))
+		var have []S
+		for {
+			v, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Read() unexpected error: %v", err)
+			}
+			have = append(have, v)
+		}
+		want := []S{{"foo", 1}, {}, {"bar", 2}}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("Read() want %+v, have %+v", want, have)
+		}
+	})
+
+	t.Run("Read pointer", func(t *testing.T) {
+		r := NewReader[*S](NewDecoder(strings.NewReader("foo  1")))
+		v, err := r.Read()
+		if err != nil {
+			t.Fatalf("Read() unexpected error: %v", err)
+		}
+		if want := (&S{"foo", 1}); !reflect.DeepEqual(v, want) {
+			t.Errorf("Read() want %+v, have %+v", want, v)
+		}
+		if _, err := r.Read(); err != io.EOF {
+			t.Errorf("Read() want io.EOF, have %v", err)
+		}
+	})
+
+	t.Run("All", func(t *testing.T) {
+		r := NewReader[S](NewDecoder(strings.NewReader("foo  1\nbar  x\nbaz  3")))
+		var (
+			have []S
+			errs int
+		)
+		r.All()(func(v S, err error) bool {
+			if err != nil {
+				if v != (S{}) {
+					t.Errorf("All() want zero value with error, have %+v", v)
+				}
+				errs++
+				return true
+			}
+			have = append(have, v)
+			return true
+		})
+		if want := []S{{"foo", 1}}; !reflect.DeepEqual(have, want) {
+			t.Errorf("All() want %+v, have %+v", want, have)
+		}
+		if errs != 1 {
+			t.Errorf("All() want 1 error, have %d", errs)
+		}
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		r := NewReader[*S](NewDecoder(strings.NewReader("001xyz")))
+		if v, err := r.Read(); err == nil || v != nil {
+			t.Errorf("Read() want nil and an error, have %+v, %v", v, err)
+		}
+	})
+
+	t.Run("All break", func(t *testing.T) {
+		r := NewReader[S](NewDecoder(strings.NewReader("foo  1\nbar  2\nbaz  3")))
+		n := 0
+		r.All()(func(v S, err error) bool {
+			n++
+			return false
+		})
+		if n != 1 {
+			t.Errorf("All() want 1 value, have %d", n)
+		}
+		if v, err := r.Read(); err != nil || v.A != "bar" {
+			t.Errorf("Read() want bar, have %+v (%v)", v, err)
+		}
+	})
+}
+
+func TestReader_Each(t *testing.T) {
+	type S struct {
+		I int `fixed:"1,5"`
+	}
+
+	var in strings.Builder
+	for i := 0; i < 1000; i++ {
+		in.WriteString(strconv.Itoa(i) + "\n")
+	}
+
+	for _, n := range []int{1, 4} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(in.String()))
+			d.SetConcurrency(n)
+
+			var have []int
+			err := NewReader[S](d).Each(func(s S) error {
+				have = append(have, s.I)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Each() unexpected error: %v", err)
+			}
+			for i, v := range have {
+				if v != i {
+					t.Fatalf("Each() value %d out of order, have %d", i, v)
+				}
+			}
+			if len(have) != 1000 {
+				t.Errorf("Each() want 1000 values, have %d", len(have))
+			}
+		})
+
+		t.Run(strconv.Itoa(n)+" stop", func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(in.String()))
+			d.SetConcurrency(n)
+
+			stop := errors.New("stop")
+			count := 0
+			err := NewReader[S](d).Each(func(s S) error {
+				count++
+				if s.I == 600 {
+					return stop
+				}
+				return nil
+			})
+			if err != stop {
+				t.Errorf("Each() want stop error, have %v", err)
+			}
+			if count != 601 {
+				t.Errorf("Each() want 601 values, have %d", count)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,3"`
+		B int    `fixed:"4,6,right"`
+	}
+
+	buf := new(bytes.Buffer)
+	e := NewEncoder(buf)
+	e.SetLineTerminator([]byte("\r\n"))
+	w := NewWriter[S](e)
+	for _, v := range []S{{"foo", 1}, {"bar", 22}} {
+		if err := w.Write(v); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("Flush() unexpected error: %v", err)
+	}
+	if want := "foo  1\r\nbar 22"; buf.String() != want {
+		t.Errorf("Write() want %q, have %q", want, buf.String())
+	}
+
+	t.Run("line too long", func(t *testing.T) {
+		type S struct {
+			A string `fixed:"1,3"`
+			B string `fixed:"4,6"`
+		}
+		e := NewEncoder(new(bytes.Buffer))
+		e.SetLineLength(4)
+		w := NewWriter[S](e)
+		if err := w.Write(S{"foo", "x"}); err != nil {
+			t.Fatalf("Write() unexpected error: %v", err)
+		}
+		var lineErr *LineLengthError
+		if err := w.Write(S{"bar", "xy"}); !errors.As(err, &lineErr) || lineErr.Line != 2 {
+			t.Errorf("Write() want error on line 2, have %v", err)
+		}
+	})
+
+	t.Run("line terminator fields", func(t *testing.T) {
+		type S struct {
+			A    string `fixed:"1,3"`
+			Term LineTerminator
+		}
+		values := []S{{"foo", LineTerminator("\r\n")}, {"bar", LineTerminator("\r")}, {"baz", nil}, {"qux", nil}}
+
+		buf := new(bytes.Buffer)
+		e := NewEncoder(buf)
+		e.SetUseLineTerminatorFields(true)
+		w := NewWriter[S](e)
+		for _, v := range values {
+			if err := w.Write(v); err != nil {
+				t.Fatalf("Write() unexpected error: %v", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatalf("Flush() unexpected error: %v", err)
+		}
+		if want := "foo\r\nbar\rbaz\nqux"; buf.String() != want {
+			t.Errorf("Write() want %q, have %q", want, buf.String())
+		}
+
+		// Encoding the values as a slice gives the same output.
+		have := buf.String()
+		buf.Reset()
+		if err := e.Encode(values); err != nil {
+			t.Fatalf("Encode() unexpected error: %v", err)
+		}
+		if buf.String() != have {
+			t.Errorf("Encode() want %q, have %q", have, buf.String())
+		}
+	})
+}
+
+func TestRecords(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,3"`
+		B int    `fixed:"4,6"`
+	}
+
+	r := NewRecordReaderBytes([]byte("foo  1bar  2bazxxx"), 6, 0)
+
+	var have []S
+	Records[S](r, 0, 2)(func(v S, err error) bool {
+		if err != nil {
+			t.Fatalf("Records() unexpected error: %v", err)
+		}
+		have = append(have, v)
+		return true
+	})
+	if want := []S{{"foo", 1}, {"bar", 2}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("Records() want %+v, have %+v", want, have)
+	}
+
+	var errs []error
+	Records[S](r, 1, 5)(func(v S, err error) bool {
+		errs = append(errs, err)
+		return true
+	})
+	if len(errs) != 2 || errs[0] != nil {
+		t.Fatalf("Records() want an error after one value, have %v", errs)
+	}
+	if _, ok := errs[1].(*UnmarshalTypeError); !ok {
+		t.Errorf("Records() want *UnmarshalTypeError, have %v", errs[1])
+	}
+}
@@ -0,0 +1,280 @@
+package fixedwidth
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// dateLayouts are the time layouts recognized by InferLayout, in order of preference.
+var dateLayouts = []string{
+	"2006-01-02",
+	"20060102",
+	"01/02/2006",
+	"02.01.2006",
+	"2006/01/02",
+	"01022006",
+}
+
+// InferLayout proposes a layout for fixed-width data given a sample of its lines.
+//
+// Field boundaries are placed at columns that are blank in every line and where every
+// line changes between a digit and a letter. Blank columns are assigned to the field
+// whose values are aligned away from them. The type of each field is guessed from its
+// values: int, decimal, date, or string. The fields are named Field1, Field2, and so
+// on.
+//
+// If useCodepointIndices is true, positions are expressed in terms of UTF-8 decoded
+// codepoints instead of bytes.
+//
+// The result is only a starting point. Columns that happen to be blank or to change
+// between digits and letters in every sample line are indistinguishable from field
+// boundaries, so larger and more varied samples give better results.
+func InferLayout(lines []string, useCodepointIndices bool) (*Layout, error) {
+	var (
+		sample [][]rune
+		width  int
+	)
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var cols []rune
+		if useCodepointIndices {
+			cols = []rune(line)
+		} else {
+			cols = make([]rune, len(line))
+			for i := 0; i < len(line); i++ {
+				cols[i] = rune(line[i])
+				if line[i] >= utf8.RuneSelf {
+					// Treat every byte of a multibyte character as a letter.
+					cols[i] = 'a'
+				}
+			}
+		}
+		sample = append(sample, cols)
+		if len(cols) > width {
+			width = len(cols)
+		}
+	}
+	if len(sample) == 0 {
+		return nil, errors.New("fixedwidth: no data to infer a layout from")
+	}
+
+	at := func(line []rune, c int) rune {
+		if c < len(line) {
+			return line[c]
+		}
+		return ' '
+	}
+
+	// Find the columns that are blank in every line.
+	blank := make([]bool, width)
+	for c := range blank {
+		blank[c] = true
+		for _, line := range sample {
+			if at(line, c) != ' ' {
+				blank[c] = false
+				break
+			}
+		}
+	}
+
+	// Group the remaining columns into runs of data. A run is split where every line
+	// changes between a digit and a letter.
+	type run struct{ start, end int } // zero based, inclusive
+	var runs []run
+	for c := 0; c < width; c++ {
+		if blank[c] {
+			continue
+		}
+		if len(runs) == 0 || runs[len(runs)-1].end != c-1 || isClassBoundary(sample, c, at) {
+			runs = append(runs, run{c, c})
+			continue
+		}
+		runs[len(runs)-1].end = c
+	}
+
+	layout := new(Layout)
+	for i, r := range runs {
+		values := make([]string, len(sample))
+		alignedLeft, alignedRight := true, true
+		for j, line := range sample {
+			raw := sliceRunes(line, r.start, r.end+1)
+			values[j] = strings.TrimSpace(string(raw))
+			if values[j] == "" {
+				continue
+			}
+			if len(raw) == 0 || raw[0] == ' ' {
+				alignedLeft = false
+			}
+			if len(raw) < r.end-r.start+1 || raw[len(raw)-1] == ' ' {
+				alignedRight = false
+			}
+		}
+
+		f := LayoutField{
+			Name:  "Field" + strconv.Itoa(i+1),
+			Start: r.start + 1,
+			End:   r.end + 1,
+		}
+		zeroPad := inferFieldType(&f, values)
+		numeric := f.Type == TypeInt || f.Type == TypeDecimal
+		switch {
+		case alignedRight && (!alignedLeft || numeric):
+			f.Alignment = string(right)
+			if zeroPad {
+				f.Pad = "0"
+			}
+		case alignedLeft && f.Type != TypeDate:
+			f.Alignment = string(left)
+		}
+		layout.Fields = append(layout.Fields, f)
+	}
+
+	// Assign the blank columns around each run. A right aligned field takes the blank
+	// columns before it, all others take the blank columns after them.
+	fields := layout.Fields
+	for i := range fields {
+		if fields[i].Alignment == string(right) {
+			if i == 0 {
+				fields[i].Start = 1
+			} else {
+				fields[i].Start = runs[i-1].end + 2
+			}
+			continue
+		}
+		switch {
+		case i == len(fields)-1:
+			fields[i].End = width
+		case fields[i+1].Alignment != string(right):
+			fields[i].End = runs[i+1].start
+		}
+	}
+
+	return layout, nil
+}
+
+// isClassBoundary reports whether column c starts a new field because every line
+// changes between a digit and a letter at c.
+func isClassBoundary(sample [][]rune, c int, at func([]rune, int) rune) bool {
+	seen := false
+	for _, line := range sample {
+		prev, cur := at(line, c-1), at(line, c)
+		if prev == ' ' || cur == ' ' {
+			continue
+		}
+		if unicode.IsDigit(prev) && unicode.IsLetter(cur) || unicode.IsLetter(prev) && unicode.IsDigit(cur) {
+			seen = true
+			continue
+		}
+		return false
+	}
+	return seen
+}
+
+// inferFieldType sets the type and format of f based on its values. It reports
+// whether the values look like numbers padded with leading zeros.
+func inferFieldType(f *LayoutField, values []string) (zeroPad bool) {
+	var (
+		nonEmpty  int
+		isInt     = true
+		isDecimal = true
+		dates     = make([]bool, len(dateLayouts))
+	)
+	for i := range dates {
+		dates[i] = true
+	}
+
+	zeroPad = true
+	width := f.End - f.Start + 1
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		nonEmpty++
+
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			isInt = false
+		}
+		if !isDecimalString(v) {
+			isDecimal = false
+		}
+		if len(v) != width {
+			zeroPad = false
+		}
+		for i, layout := range dateLayouts {
+			if !dates[i] {
+				continue
+			}
+			if len(v) != len(layout) {
+				dates[i] = false
+				continue
+			}
+			if t, err := time.Parse(layout, v); err != nil || t.Year() < 1800 || t.Year() > 2200 {
+				dates[i] = false
+			}
+		}
+	}
+
+	f.Type = TypeString
+	if nonEmpty == 0 {
+		return false
+	}
+	for i, ok := range dates {
+		if ok {
+			f.Type = TypeDate
+			f.Format = dateLayouts[i]
+			return false
+		}
+	}
+	switch {
+	case isInt:
+		f.Type = TypeInt
+	case isDecimal:
+		f.Type = TypeDecimal
+	default:
+		return false
+	}
+	return zeroPad && hasLeadingZero(values)
+}
+
+// isDecimalString reports whether s is a plain decimal number such as 12, -1.5,
+// or .25.
+func isDecimalString(s string) bool {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	digits, dot := 0, false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '.' && !dot:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+func hasLeadingZero(values []string) bool {
+	for _, v := range values {
+		if len(v) > 1 && v[0] == '0' {
+			return true
+		}
+	}
+	return false
+}
+
+func sliceRunes(r []rune, start, end int) []rune {
+	if start > len(r) {
+		return nil
+	}
+	if end > len(r) {
+		end = len(r)
+	}
+	return r[start:end]
+}
@@ -0,0 +1,74 @@
+package fixedwidth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferLayout(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		lines      []string
+		codepoints bool
+		want       []LayoutField
+	}{
+		{
+			name: "blank separated",
+			lines: []string{
+				"1    Ian       Lopshire     99.50 2019-01-02",
+				"2    John      Doe           9.50 2020-11-30",
+				"30   Jane      Doe         179.50 2021-06-15",
+			},
+			want: []LayoutField{
+				{Name: "Field1", Start: 1, End: 5, Alignment: "left", Type: TypeInt},
+				{Name: "Field2", Start: 6, End: 15, Alignment: "left", Type: TypeString},
+				{Name: "Field3", Start: 16, End: 23, Alignment: "left", Type: TypeString},
+				{Name: "Field4", Start: 24, End: 33, Alignment: "right", Type: TypeDecimal},
+				{Name: "Field5", Start: 35, End: 44, Type: TypeDate, Format: "2006-01-02"},
+			},
+		},
+		{
+			name: "digit letter boundary",
+			lines: []string{
+				"00001Ian  20190102",
+				"00042Jane 20200229",
+			},
+			want: []LayoutField{
+				{Name: "Field1", Start: 1, End: 5, Alignment: "right", Pad: "0", Type: TypeInt},
+				{Name: "Field2", Start: 6, End: 10, Alignment: "left", Type: TypeString},
+				{Name: "Field3", Start: 11, End: 18, Type: TypeDate, Format: "20060102"},
+			},
+		},
+		{
+			name: "codepoints",
+			lines: []string{
+				"ÅÑ   12",
+				"Ñ☃☃ 345",
+			},
+			codepoints: true,
+			want: []LayoutField{
+				{Name: "Field1", Start: 1, End: 3, Alignment: "left", Type: TypeString},
+				{Name: "Field2", Start: 4, End: 7, Alignment: "right", Type: TypeInt},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := InferLayout(tt.lines, tt.codepoints)
+			if err != nil {
+				t.Fatalf("InferLayout() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(layout.Fields, tt.want) {
+				t.Errorf("InferLayout() want\n%+v\nhave\n%+v", tt.want, layout.Fields)
+			}
+			if err := layout.Validate(); err != nil {
+				t.Errorf("Validate() unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("no data", func(t *testing.T) {
+		if _, err := InferLayout([]string{"", "  "}, false); err == nil {
+			t.Errorf("InferLayout() expected error")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+// Package gentest contains types with encoders and decoders generated by
+// fixedwidth-gen. It is used to test that generated code behaves in the same way as
+// the reflection based Encoder and Decoder.
+package gentest
+
+import (
+	"time"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+//go:generate go run ../../cmd/fixedwidth-gen
+
+// Person exercises every kind of field supported by fixedwidth-gen.
+type Person struct {
+	ID        int       `fixed:"1,5,right,0"`
+	FirstName string    `fixed:"6,15"`
+	LastName  string    `fixed:"16,25,left"`
+	Age       uint8     `fixed:"26,28,right"`
+	Score     float64   `fixed:"29,36,right"`
+	Ratio     float32   `fixed:"37,42,left,_"`
+	Active    bool      `fixed:"43,47"`
+	Nickname  *string   `fixed:"48,55"`
+	Rank      *int64    `fixed:"56,59,right"`
+	Joined    time.Time `fixed:"60,79"`
+	Code      Code      `fixed:"80,83,none"`
+	Skip      string
+}
+
+// Account exercises fixedwidth.RawLine, LineNumber, and LineOffset fields.
+type Account struct {
+	fixedwidth.RawLine
+	Line   fixedwidth.LineNumber
+	Offset fixedwidth.LineOffset
+	ID     int    `fixed:"1,5,right,0"`
+	Status string `fixed:"11,12"`
+}
+
+// Code is a string type with its own underlying kind.
+type Code string
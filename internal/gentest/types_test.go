@@ -0,0 +1,238 @@
+package gentest
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ianlopshire/go-fixedwidth"
+)
+
+// plainPerson has the fields of Person but not its generated methods, so it is
+// encoded and decoded using reflection.
+type plainPerson Person
+
+func TestGenerated_Marshal(t *testing.T) {
+	nickname, rank := "Ace", int64(-42)
+	for _, tt := range []struct {
+		name string
+		v    Person
+	}{
+		{"zero", Person{}},
+		{"all fields", Person{
+			ID:        42,
+			FirstName: "John",
+			LastName:  "Doe",
+			Age:       37,
+			Score:     1234.567,
+			Ratio:     0.5,
+			Active:    true,
+			Nickname:  &nickname,
+			Rank:      &rank,
+			Joined:    time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+			Code:      "ABCD",
+		}},
+		{"truncated", Person{ID: 1234567, FirstName: "Bartholomew", Score: -12345678.9, Code: "ABCDEF"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fixedwidth.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			want, err := fixedwidth.Marshal(plainPerson(tt.v))
+			if err != nil {
+				t.Fatalf("Marshal() unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Marshal() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestGenerated_Unmarshal(t *testing.T) {
+	const line = "00042John      Doe        37 1234.570.5   true Ace      -422020-01-02T03:04:05ZABCD"
+	// with returns line with the value at the 1-based position start replaced by s.
+	with := func(start int, s string) string {
+		return line[:start-1] + s + line[start-1+len(s):]
+	}
+
+	for _, tt := range []struct {
+		name    string
+		line    string
+		wantErr bool
+	}{
+		{"all fields", line, false},
+		{"blank fields", with(6, "                                              "), false},
+		{"short", line[:59] + "2021-03-04T00:00:00Z", false},
+		{"padded", with(6, "  John    "), false},
+		{"invalid int", with(1, "0004x"), true},
+		{"invalid uint", with(26, "-37"), true},
+		{"invalid float", with(29, "    1e1e"), true},
+		{"invalid bool", with(43, "maybe"), true},
+		{"invalid pointer", with(56, " abc"), true},
+		{"invalid text", with(60, "yesterday           "), true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Person
+			gotErr := fixedwidth.Unmarshal([]byte(tt.line), &got)
+			var want plainPerson
+			wantErr := fixedwidth.Unmarshal([]byte(tt.line), &want)
+
+			if (gotErr != nil) != tt.wantErr || (wantErr != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, want %v", gotErr, wantErr)
+			}
+			if gotErr != nil {
+				var gotTypeErr, wantTypeErr *fixedwidth.UnmarshalTypeError
+				if !errors.As(gotErr, &gotTypeErr) || !errors.As(wantErr, &wantTypeErr) {
+					t.Fatalf("Unmarshal() error = %v, want %v", gotErr, wantErr)
+				}
+				if gotTypeErr.Value != wantTypeErr.Value ||
+					gotTypeErr.Type != wantTypeErr.Type ||
+					gotTypeErr.Field != wantTypeErr.Field ||
+					gotTypeErr.Line != wantTypeErr.Line ||
+					gotTypeErr.Cause.Error() != wantTypeErr.Cause.Error() {
+					t.Errorf("Unmarshal() error = %v, want %v", gotErr, wantErr)
+				}
+				return
+			}
+			if !reflect.DeepEqual(plainPerson(got), want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestGenerated_Decoder(t *testing.T) {
+	const joined = "2020-01-02T03:04:05Z"
+	input := "00001Alice" + strings.Repeat(" ", 49) + joined + "\n" +
+		"00002Bob" + strings.Repeat(" ", 51) + joined + "\n" +
+		"0000xCarol" + strings.Repeat(" ", 49) + joined + "\n"
+	d := fixedwidth.NewDecoder(bytes.NewReader([]byte(input)))
+	var got []Person
+	err := d.Decode(&got)
+
+	var typeErr *fixedwidth.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Line != 3 || typeErr.Field != "ID" {
+		t.Fatalf("Decode() error = %v, want error for field ID on line 3", err)
+	}
+	if len(got) != 2 || got[0].FirstName != "Alice" || got[1].ID != 2 {
+		t.Errorf("Decode() = %+v", got)
+	}
+}
+
+func TestGenerated_SetUseCodepointIndices(t *testing.T) {
+	const joined = "2020-01-02T03:04:05Z"
+	snowmen := strings.Repeat("☃", 10)
+	input := "00001Alice" + strings.Repeat(" ", 49) + joined + "\n" +
+		"00002" + snowmen + strings.Repeat(" ", 44) + joined + "\n"
+
+	// The generated methods use byte indices, so they must not be used once codepoint
+	// indices are turned on between two calls.
+	d := fixedwidth.NewDecoder(strings.NewReader(input))
+	var p Person
+	if err := d.Decode(&p); err != nil || p.FirstName != "Alice" {
+		t.Fatalf("Decode() = %+v, %v, want FirstName Alice", p, err)
+	}
+	d.SetUseCodepointIndices(true)
+	if err := d.Decode(&p); err != nil || p.FirstName != snowmen {
+		t.Fatalf("Decode() with codepoint indices = %q, %v, want FirstName %q", p.FirstName, err, snowmen)
+	}
+
+	var buf bytes.Buffer
+	e := fixedwidth.NewEncoder(&buf)
+	if err := e.Encode(Person{FirstName: "Alice"}); err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	buf.Reset()
+	e.SetUseCodepointIndices(true)
+	if err := e.Encode(p); err != nil {
+		t.Fatalf("Encode() with codepoint indices unexpected error: %v", err)
+	}
+	var want bytes.Buffer
+	pe := fixedwidth.NewEncoder(&want)
+	pe.SetUseCodepointIndices(true)
+	if err := pe.Encode(plainPerson(p)); err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("Encode() with codepoint indices = %q, want %q", buf.String(), want.String())
+	}
+}
+
+// plainAccount is to Account what plainPerson is to Person.
+type plainAccount Account
+
+func TestGenerated_RawLine(t *testing.T) {
+	for _, line := range []string{"00042keep-OKtrailer", "00042ab"} {
+		var got Account
+		if err := fixedwidth.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("Unmarshal() unexpected error: %v", err)
+		}
+		var want plainAccount
+		if err := fixedwidth.Unmarshal([]byte(line), &want); err != nil {
+			t.Fatalf("Unmarshal() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(plainAccount(got), want) {
+			t.Errorf("Unmarshal(%q) = %+v, want %+v", line, got, want)
+		}
+
+		got.Status, want.Status = "N", "N"
+		gotLine, err := fixedwidth.Marshal(got)
+		if err != nil {
+			t.Fatalf("Marshal() unexpected error: %v", err)
+		}
+		wantLine, err := fixedwidth.Marshal(want)
+		if err != nil {
+			t.Fatalf("Marshal() unexpected error: %v", err)
+		}
+		if !bytes.Equal(gotLine, wantLine) {
+			t.Errorf("Marshal() = %q, want %q", gotLine, wantLine)
+		}
+	}
+}
+
+func TestGenerated_LineMetadata(t *testing.T) {
+	var got []Account
+	if err := fixedwidth.Unmarshal([]byte("00001     OK\n00002     NO\n"), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if len(got) != 2 || got[0].Line != 1 || got[0].Offset != 0 || got[1].Line != 2 || got[1].Offset != 13 {
+		t.Errorf("Unmarshal() = %+v, want lines 1 and 2 at offsets 0 and 13", got)
+	}
+}
+
+var benchLine = []byte("00042John      Doe        37 1234.570.5   true Ace      -422020-01-02T03:04:05ZABCD")
+
+func BenchmarkUnmarshal_Generated(b *testing.B) {
+	var v Person
+	for i := 0; i < b.N; i++ {
+		_ = fixedwidth.Unmarshal(benchLine, &v)
+	}
+}
+
+func BenchmarkUnmarshal_Reflection(b *testing.B) {
+	var v plainPerson
+	for i := 0; i < b.N; i++ {
+		_ = fixedwidth.Unmarshal(benchLine, &v)
+	}
+}
+
+func BenchmarkMarshal_Generated(b *testing.B) {
+	var v Person
+	_ = fixedwidth.Unmarshal(benchLine, &v)
+	for i := 0; i < b.N; i++ {
+		_, _ = fixedwidth.Marshal(v)
+	}
+}
+
+func BenchmarkMarshal_Reflection(b *testing.B) {
+	var v plainPerson
+	_ = fixedwidth.Unmarshal(benchLine, &v)
+	for i := 0; i < b.N; i++ {
+		_, _ = fixedwidth.Marshal(v)
+	}
+}
@@ -0,0 +1,69 @@
+// Package parse parses values directly from the bytes of a fixed-width line so that
+// decoding a numeric or boolean field does not allocate. Inputs outside of the fast
+// paths are handed to strconv, which keeps the results and errors identical to it.
+//
+// It is shared by the Decoder and by the code generated by fixedwidth-gen.
+package parse
+
+import "strconv"
+
+// maxIntDigits is the number of decimal digits that always fit in an int.
+const maxIntDigits = strconv.IntSize*3/10 - 1
+
+// Int parses b as a base 10 int in the same way as strconv.Atoi.
+func Int(b []byte) (int, error) {
+	if n := len(b); n > 0 && n <= maxIntDigits {
+		s := b
+		if b[0] == '-' || b[0] == '+' {
+			s = b[1:]
+		}
+		if len(s) > 0 {
+			var v int
+			for _, c := range s {
+				if c < '0' || c > '9' {
+					return strconv.Atoi(string(b))
+				}
+				v = v*10 + int(c-'0')
+			}
+			if b[0] == '-' {
+				v = -v
+			}
+			return v, nil
+		}
+	}
+	return strconv.Atoi(string(b))
+}
+
+// Uint parses b as a base 10 uint64 in the same way as strconv.ParseUint.
+func Uint(b []byte) (uint64, error) {
+	if n := len(b); n > 0 && n < 20 {
+		// 19 decimal digits always fit in a uint64.
+		var v uint64
+		for _, c := range b {
+			if c < '0' || c > '9' {
+				return strconv.ParseUint(string(b), 10, 64)
+			}
+			v = v*10 + uint64(c-'0')
+		}
+		return v, nil
+	}
+	return strconv.ParseUint(string(b), 10, 64)
+}
+
+// Float parses b as a floating-point number in the same way as
+// strconv.ParseFloat. strconv does not retain its argument, so the conversion of short
+// inputs is done without allocating.
+func Float(b []byte, bitSize int) (float64, error) {
+	return strconv.ParseFloat(string(b), bitSize)
+}
+
+// Bool parses b as a boolean in the same way as strconv.ParseBool.
+func Bool(b []byte) (bool, error) {
+	switch string(b) {
+	case "1", "t", "T", "TRUE", "true", "True":
+		return true, nil
+	case "0", "f", "F", "FALSE", "false", "False":
+		return false, nil
+	}
+	return strconv.ParseBool(string(b))
+}
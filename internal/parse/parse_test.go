@@ -0,0 +1,43 @@
+package parse
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestInt(t *testing.T) {
+	for _, s := range []string{
+		"", "0", "42", "-42", "+42", "-", "+", "4.2", "a", " 42",
+		"9223372036854775807", "-9223372036854775808", "9223372036854775808",
+		"123456789012345678", "1234567890123456789",
+	} {
+		want, wantErr := strconv.Atoi(s)
+		have, err := Int([]byte(s))
+		if have != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("Int(%q) = %v, %v; strconv.Atoi returned %v, %v", s, have, err, want, wantErr)
+		}
+	}
+}
+
+func TestUint(t *testing.T) {
+	for _, s := range []string{
+		"", "0", "42", "-42", "+42", "4.2", "a",
+		"18446744073709551615", "18446744073709551616", "1234567890123456789",
+	} {
+		want, wantErr := strconv.ParseUint(s, 10, 64)
+		have, err := Uint([]byte(s))
+		if have != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("Uint(%q) = %v, %v; strconv.ParseUint returned %v, %v", s, have, err, want, wantErr)
+		}
+	}
+}
+
+func TestBool(t *testing.T) {
+	for _, s := range []string{"", "1", "t", "T", "true", "TRUE", "True", "0", "f", "F", "false", "FALSE", "False", "yes", "tRuE"} {
+		want, wantErr := strconv.ParseBool(s)
+		have, err := Bool([]byte(s))
+		if have != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("Bool(%q) = %v, %v; strconv.ParseBool returned %v, %v", s, have, err, want, wantErr)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package fixedwidth
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+// A Layout describes the fields of a fixed-width record. It is an alternative to
+// `fixed` struct tags for data whose shape is only known at run time, for example
+// when it is loaded from a JSON file.
+type Layout struct {
+	Fields []LayoutField `json:"fields"`
+}
+
+// A LayoutField describes a single field of a Layout.
+//
+// Start and End follow the same rules as the positions of the `fixed` struct tag:
+// positions start at 1 and the interval is inclusive. Alignment is one of default,
+// left, right, or none and may be omitted. Pad is the single padding character and
+// defaults to a space.
+//
+// Type is a hint describing the kind of data held by the field. It is one of string,
+// int, decimal, or date and may be omitted. The value of a field is always decoded
+// as a string regardless of its type. Format is the Go time layout of a date field.
+//
+// An int or decimal field padded with zeros that only holds zeros, such as 00000,
+// decodes as "0" rather than as an empty value.
+type LayoutField struct {
+	Name      string `json:"name"`
+	Start     int    `json:"start"`
+	End       int    `json:"end"`
+	Alignment string `json:"alignment,omitempty"`
+	Pad       string `json:"pad,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Format    string `json:"format,omitempty"`
+}
+
+// Valid layout field types.
+const (
+	TypeString  = "string"
+	TypeInt     = "int"
+	TypeDecimal = "decimal"
+	TypeDate    = "date"
+)
+
+// Len returns the length of a record described by the layout. That is the largest
+// end position of any of its fields.
+func (l *Layout) Len() int {
+	var ll int
+	for _, f := range l.Fields {
+		if f.End > ll {
+			ll = f.End
+		}
+	}
+	return ll
+}
+
+// Index returns the index of the field with the given name, or -1 if the layout
+// does not contain the field.
+func (l *Layout) Index(name string) int {
+	for i, f := range l.Fields {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate reports whether the layout is well-formed.
+func (l *Layout) Validate() error {
+	if len(l.Fields) == 0 {
+		return errors.New("fixedwidth: layout has no fields")
+	}
+	seen := make(map[string]bool, len(l.Fields))
+	for _, f := range l.Fields {
+		if f.Name == "" {
+			return errors.New("fixedwidth: layout field at " + strconv.Itoa(f.Start) + " has no name")
+		}
+		if seen[f.Name] {
+			return errors.New("fixedwidth: duplicate layout field " + f.Name)
+		}
+		seen[f.Name] = true
+
+		if f.Start < 1 || f.End < f.Start {
+			return errors.New("fixedwidth: invalid interval for layout field " + f.Name)
+		}
+		if f.Alignment != "" && !alignment(f.Alignment).Valid() {
+			return errors.New("fixedwidth: invalid alignment " + f.Alignment + " for layout field " + f.Name)
+		}
+		if len(f.Pad) > 1 {
+			return errors.New("fixedwidth: invalid pad character " + f.Pad + " for layout field " + f.Name)
+		}
+		switch f.Type {
+		case "", TypeString, TypeInt, TypeDecimal, TypeDate:
+		default:
+			return errors.New("fixedwidth: invalid type " + f.Type + " for layout field " + f.Name)
+		}
+	}
+	return nil
+}
+
+// fieldSpec returns the fieldSpec equivalent to the layout field.
+func (f LayoutField) fieldSpec() fieldSpec {
+	format := defaultFormat
+	if f.Alignment != "" {
+		format.alignment = alignment(f.Alignment)
+	}
+	if f.Pad != "" {
+		format.padChar = f.Pad[0]
+	}
+	return fieldSpec{
+		startPos: f.Start,
+		endPos:   f.End,
+		format:   format,
+		ok:       true,
+	}
+}
+
+// A Record holds the values of a single line decoded using a Layout.
+//
+// A pointer to a Record can be passed to Decoder.Decode and a Record can be passed to
+// Encoder.Encode in the same way as a struct with `fixed` tags. The Layout must be
+// set before a Record is decoded.
+type Record struct {
+	Layout *Layout
+
+	// Values holds the value of each field. Values[i] is the value of
+	// Layout.Fields[i].
+	Values []string
+}
+
+// NewRecord returns an empty record for the layout.
+func (l *Layout) NewRecord() *Record {
+	return &Record{Layout: l, Values: make([]string, len(l.Fields))}
+}
+
+// Get returns the value of the field with the given name. ok is false if the layout
+// does not contain the field.
+func (r *Record) Get(name string) (value string, ok bool) {
+	i := r.Layout.Index(name)
+	if i < 0 || i >= len(r.Values) {
+		return "", false
+	}
+	return r.Values[i], true
+}
+
+var (
+	recordType    = reflect.TypeOf(Record{})
+	recordPtrType = reflect.TypeOf(&Record{})
+
+	errNoLayout = errors.New("fixedwidth: Record has no Layout")
+)
+
+// recordSetter decodes a line into a *Record. A nil pointer or a record without a
+// layout cannot be decoded because there is nothing describing its fields.
+func recordSetter(v reflect.Value, raw rawBytes) error {
+	if v.IsNil() {
+		return errNoLayout
+	}
+	r := v.Interface().(*Record)
+	if r.Layout == nil {
+		return errNoLayout
+	}
+
+	if len(r.Values) != len(r.Layout.Fields) {
+		r.Values = make([]string, len(r.Layout.Fields))
+	}
+	for i, f := range r.Layout.Fields {
+		spec := f.fieldSpec()
+		value := rawValueFromLine(raw, spec.startPos, spec.endPos, spec.format)
+		if len(value.data) == 0 && f.keepsZero() {
+			// The field may have been zero rather than empty.
+			untrimmed := format{alignment: alignmentNone}
+			if len(rawValueFromLine(raw, spec.startPos, spec.endPos, untrimmed).data) > 0 {
+				r.Values[i] = "0"
+				continue
+			}
+		}
+		r.Values[i] = string(value.data)
+	}
+	return nil
+}
+
+// keepsZero reports whether f is a numeric field whose padding is trimmed down to a
+// single zero.
+func (f LayoutField) keepsZero() bool {
+	return f.Pad == "0" && (f.Type == TypeInt || f.Type == TypeDecimal)
+}
+
+func recordEncoder(useCodepointIndices bool) valueEncoder {
+	enc := stringEncoder(useCodepointIndices)
+	return func(v reflect.Value) (rawValue, error) {
+		r := v.Interface().(Record)
+		if r.Layout == nil {
+			return rawValue{}, errNoLayout
+		}
+		ll := r.Layout.Len()
+		if ll == 0 {
+			return rawValue{}, nil
+		}
+
+		// Add a 10% headroom to the builder when codepoint indices are being used.
+		c := ll
+		if useCodepointIndices {
+			c = int(1.1*float64(ll)) + 1
+		}
+		b := newLineBuilder(ll, c, ' ')
+
+		for i, f := range r.Layout.Fields {
+			var s string
+			if i < len(r.Values) {
+				s = r.Values[i]
+			}
+			if err := enc.Write(b, reflect.ValueOf(s), f.fieldSpec()); err != nil {
+				return rawValue{}, err
+			}
+		}
+		return b.AsRawValue(), nil
+	}
+}
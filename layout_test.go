@@ -0,0 +1,107 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestLayout_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		fields    []LayoutField
+		shouldErr bool
+	}{
+		{"valid", []LayoutField{{Name: "A", Start: 1, End: 5}, {Name: "B", Start: 6, End: 10, Alignment: "right", Pad: "0", Type: TypeInt}}, false},
+		{"no fields", nil, true},
+		{"no name", []LayoutField{{Start: 1, End: 5}}, true},
+		{"duplicate name", []LayoutField{{Name: "A", Start: 1, End: 5}, {Name: "A", Start: 6, End: 10}}, true},
+		{"zero start", []LayoutField{{Name: "A", Start: 0, End: 5}}, true},
+		{"invalid interval", []LayoutField{{Name: "A", Start: 5, End: 1}}, true},
+		{"invalid alignment", []LayoutField{{Name: "A", Start: 1, End: 5, Alignment: "center"}}, true},
+		{"invalid pad", []LayoutField{{Name: "A", Start: 1, End: 5, Pad: "00"}}, true},
+		{"invalid type", []LayoutField{{Name: "A", Start: 1, End: 5, Type: "money"}}, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Layout{Fields: tt.fields}
+			if err := l.Validate(); tt.shouldErr != (err != nil) {
+				t.Errorf("Validate() err want %v, have %v (%v)", tt.shouldErr, err != nil, err)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	layout := &Layout{Fields: []LayoutField{
+		{Name: "ID", Start: 1, End: 5, Alignment: "right", Pad: "0"},
+		{Name: "Name", Start: 6, End: 15},
+		{Name: "City", Start: 16, End: 20, Alignment: "left", Pad: "#"},
+	}}
+
+	t.Run("decode", func(t *testing.T) {
+		data := []byte("00001Ian       Den##\n00042Jane      ☃###")
+		dec := NewDecoder(bytes.NewReader(data))
+		dec.SetUseCodepointIndices(true)
+
+		var have [][]string
+		rec := layout.NewRecord()
+		for {
+			if err := dec.Decode(rec); err != nil {
+				break
+			}
+			have = append(have, append([]string(nil), rec.Values...))
+		}
+
+		want := [][]string{{"1", "Ian", "Den"}, {"42", "Jane", "☃"}}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("Decode() want %q, have %q", want, have)
+		}
+		if v, ok := rec.Get("Name"); !ok || v != "Jane" {
+			t.Errorf("Get() want %q, have %q", "Jane", v)
+		}
+	})
+
+	t.Run("decode zero", func(t *testing.T) {
+		layout := &Layout{Fields: []LayoutField{
+			{Name: "Amount", Start: 1, End: 5, Alignment: "right", Pad: "0", Type: TypeInt},
+			{Name: "Code", Start: 6, End: 8, Alignment: "right", Pad: "0"},
+		}}
+		var have [][]string
+		for _, line := range []string{"00000000", "00042007", "        ", ""} {
+			rec := layout.NewRecord()
+			if err := Unmarshal([]byte(line), rec); err != nil && line != "" {
+				t.Fatalf("Unmarshal(%q) unexpected error: %v", line, err)
+			}
+			have = append(have, rec.Values)
+		}
+		want := [][]string{{"0", ""}, {"42", "7"}, {"     ", "   "}, {"", ""}}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("Unmarshal() want %q, have %q", want, have)
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		enc := NewEncoder(buf)
+		enc.SetUseCodepointIndices(true)
+		err := enc.Encode([]Record{
+			{Layout: layout, Values: []string{"1", "Ian", "Den"}},
+			{Layout: layout, Values: []string{"42", "Jane", "☃"}},
+		})
+		if err != nil {
+			t.Fatalf("Encode() unexpected error: %v", err)
+		}
+		if want := "00001Ian       Den##\n00042Jane      ☃####"; buf.String() != want {
+			t.Errorf("Encode() want %q, have %q", want, buf.String())
+		}
+	})
+
+	t.Run("no layout", func(t *testing.T) {
+		if err := Unmarshal([]byte("foo"), &Record{}); err == nil {
+			t.Errorf("Unmarshal() expected error")
+		}
+		if _, err := Marshal(Record{}); err == nil {
+			t.Errorf("Marshal() expected error")
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+)
+
+// ErrNotFound is returned by RecordReader.Lookup when no record matches the key.
+var ErrNotFound = errors.New("fixedwidth: no record matches the key")
+
+// Lookup binary searches the records of r for those whose key fields match key, and
+// decodes them into v. The records must be sorted by the key fields.
+//
+// key is a struct, or a pointer to one, and fields are the names of its key fields in
+// order of significance. The key fields are encoded using their struct tags and
+// compared with the same columns of each record byte by byte, so the tags must
+// describe the alignment and padding of the key columns in the input. For example, a
+// numeric key that is padded on the left in the input needs a `right` alignment.
+//
+// If v points to a slice, every matching record is decoded and appended to it. Otherwise
+// the first matching record is decoded into the value pointed to by v. If no record
+// matches, ErrNotFound is returned.
+func (r *RecordReader) Lookup(v interface{}, key interface{}, fields ...string) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+
+	cols, err := r.keyColumns(key, fields)
+	if err != nil {
+		return err
+	}
+
+	// Find the first record that is not less than the key.
+	lo, hi := 0, r.Len()
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		c, err := r.compareRecord(mid, cols)
+		if err != nil {
+			return err
+		}
+		if c < 0 {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	if rv.Elem().Kind() != reflect.Slice {
+		if c, err := r.compareRecord(lo, cols); err != nil || c != 0 {
+			return notFound(err)
+		}
+		return r.ReadRecord(lo, v)
+	}
+
+	s := rv.Elem()
+	setter := r.d.valueSetter(s.Type().Elem())
+	n := s.Len()
+	for i := lo; ; i++ {
+		c, err := r.compareRecord(i, cols)
+		if err == ErrRecordIndex || err == nil && c != 0 {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := r.readRecordWith(i, appendZero(s), setter); err != nil {
+			s.SetLen(s.Len() - 1)
+			return err
+		}
+	}
+	if s.Len() == n {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// notFound returns err, or ErrNotFound if there was no error. A record index past the
+// last record means that the key is greater than every record.
+func notFound(err error) error {
+	if err == nil || err == ErrRecordIndex {
+		return ErrNotFound
+	}
+	return err
+}
+
+// A keyColumn is the encoded value of a key field and the position of its column.
+type keyColumn struct {
+	startPos, endPos int
+	value            []byte
+}
+
+// keyColumns encodes the named fields of key.
+func (r *RecordReader) keyColumns(key interface{}, fields []string) ([]keyColumn, error) {
+	kv := reflect.Indirect(reflect.ValueOf(key))
+	if kv.Kind() != reflect.Struct {
+		return nil, errors.New("fixedwidth: Lookup key must be a struct")
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("fixedwidth: Lookup requires at least one key field")
+	}
+
+	t := kv.Type()
+	ss := cachedStructSpec(t)
+	b := newLineBuilder(ss.ll, ss.ll, ' ')
+	specs := make([]fieldSpec, len(fields))
+	for i, name := range fields {
+		sf, ok := t.FieldByName(name)
+		if !ok || len(sf.Index) != 1 || !ss.fieldSpecs[sf.Index[0]].ok {
+			return nil, errors.New("fixedwidth: unknown key field " + t.Name() + "." + name)
+		}
+		specs[i] = ss.fieldSpecs[sf.Index[0]]
+		err := specs[i].getEncoder(r.d.useCodepointIndices).Write(b, kv.Field(sf.Index[0]), specs[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	line := newRawBytes(b.data, r.d.useCodepointIndices)
+	cols := make([]keyColumn, len(fields))
+	for i, spec := range specs {
+		cols[i] = keyColumn{
+			startPos: spec.startPos,
+			endPos:   spec.endPos,
+			value:    columnOf(line, spec.startPos, spec.endPos),
+		}
+	}
+	return cols, nil
+}
+
+// compareRecord compares the key columns of the record with index i with cols. The
+// result is negative if the record sorts before the key, 0 if it matches, and positive
+// otherwise.
+func (r *RecordReader) compareRecord(i int, cols []keyColumn) (int, error) {
+	record, err := r.record(i)
+	if err != nil {
+		return 0, err
+	}
+	line := newRawBytes(record, r.d.useCodepointIndices)
+	for _, col := range cols {
+		if c := bytes.Compare(columnOf(line, col.startPos, col.endPos), col.value); c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
+
+// columnOf returns the untrimmed data between the positions start and end of line.
+func columnOf(line rawBytes, start, end int) []byte {
+	return rawValueFromLine(line, start, end, format{alignment: alignmentNone}).data
+}
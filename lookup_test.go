@@ -0,0 +1,92 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRecordReader_Lookup(t *testing.T) {
+	type Zip struct {
+		State string `fixed:"1,2"`
+		Code  int    `fixed:"3,7,right,0"`
+		City  string `fixed:"8,18"`
+	}
+
+	data := []byte("" +
+		"CA90001Los Angeles\n" +
+		"CA94105San Fran   \n" +
+		"NY00501Holtsville \n" +
+		"NY10001New York   \n" +
+		"NY10001Manhattan  \n" +
+		"TX73301Austin     \n")
+	readers := map[string]*RecordReader{
+		"Bytes":    NewRecordReaderBytes(data, 18, 1),
+		"ReaderAt": NewRecordReader(bytes.NewReader(data), int64(len(data)), 18, 1),
+	}
+
+	for name, r := range readers {
+		t.Run(name, func(t *testing.T) {
+			var z Zip
+			if err := r.Lookup(&z, Zip{State: "NY", Code: 501}, "State", "Code"); err != nil {
+				t.Fatalf("Lookup() unexpected error: %v", err)
+			}
+			if want := (Zip{"NY", 501, "Holtsville"}); z != want {
+				t.Errorf("Lookup() want %+v, have %+v", want, z)
+			}
+
+			var zs []Zip
+			if err := r.Lookup(&zs, &Zip{State: "NY", Code: 10001}, "State", "Code"); err != nil {
+				t.Fatalf("Lookup() unexpected error: %v", err)
+			}
+			if want := []Zip{{"NY", 10001, "New York"}, {"NY", 10001, "Manhattan"}}; !reflect.DeepEqual(zs, want) {
+				t.Errorf("Lookup() want %+v, have %+v", want, zs)
+			}
+
+			zs = nil
+			if err := r.Lookup(&zs, Zip{State: "CA"}, "State"); err != nil {
+				t.Fatalf("Lookup() unexpected error: %v", err)
+			}
+			if len(zs) != 2 || zs[0].City != "Los Angeles" || zs[1].City != "San Fran" {
+				t.Errorf("Lookup() want both CA records, have %+v", zs)
+			}
+
+			for _, key := range []Zip{{State: "AA"}, {State: "NY", Code: 9999}, {State: "ZZ"}} {
+				if err := r.Lookup(&z, key, "State", "Code"); err != ErrNotFound {
+					t.Errorf("Lookup(%+v) want ErrNotFound, have %v", key, err)
+				}
+				if err := r.Lookup(&zs, key, "State", "Code"); err != ErrNotFound {
+					t.Errorf("Lookup(%+v) want ErrNotFound, have %v", key, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordReader_Lookup_errors(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,3"`
+		B string
+	}
+	r := NewRecordReaderBytes([]byte("foobar"), 3, 0)
+
+	var s S
+	for _, tt := range []struct {
+		name   string
+		v      interface{}
+		key    interface{}
+		fields []string
+	}{
+		{"non-pointer", s, S{A: "foo"}, []string{"A"}},
+		{"non-struct key", &s, "foo", []string{"A"}},
+		{"no fields", &s, S{A: "foo"}, nil},
+		{"unknown field", &s, S{A: "foo"}, []string{"C"}},
+		{"untagged field", &s, S{A: "foo"}, []string{"B"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Lookup(tt.v, tt.key, tt.fields...); err == nil || err == ErrNotFound {
+				t.Errorf("Lookup() want error, have %v", err)
+			}
+		})
+	}
+}
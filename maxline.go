@@ -0,0 +1,80 @@
+package fixedwidth
+
+import (
+	"bufio"
+	"errors"
+	"strconv"
+)
+
+// A LineTooLongError is returned by the Decoder when a line is longer than it can
+// buffer. It wraps ErrTooLong.
+type LineTooLongError struct {
+	Line int // line of the input, starting at 1
+	Max  int // maximum line length in bytes, excluding the line terminator
+}
+
+func (e *LineTooLongError) Error() string {
+	return "fixedwidth: line " + strconv.Itoa(e.Line) + ": longer than " + strconv.Itoa(e.Max) + " bytes"
+}
+
+func (e *LineTooLongError) Unwrap() error {
+	return ErrTooLong
+}
+
+// SetMaxLineLength sets the maximum length in bytes of a line, excluding its
+// terminator, or of a record when a record length is set. Longer lines give a
+// *LineTooLongError. Raise it to decode very wide records, or lower it to bound the
+// memory used for untrusted input. The Decoder buffers at most one line of this length,
+// plus its terminator.
+//
+// Unlike SetLineLengthRange, which checks lines after they have been read, the limit
+// applies while a line is being read. The default limit is bufio.MaxScanTokenSize-1
+// bytes for a line terminated by "\n". SetMaxLineLength must be called before the first
+// call to Decode.
+func (d *Decoder) SetMaxLineLength(n int) {
+	d.lineLimit = n
+}
+
+// Buffer is like SetMaxLineLength, but also sets the initial buffer used to read lines.
+// If the capacity of buf is enough for the longest line, it is the only buffer the
+// Decoder uses. Buffer must be called before the first call to Decode.
+func (d *Decoder) Buffer(buf []byte, max int) {
+	d.buf = buf
+	d.lineLimit = max
+}
+
+// startScan configures the scanner before the first line is read, once the line
+// terminators are known.
+func (d *Decoder) startScan() {
+	d.scanStarted = true
+	if d.lineLimit > 0 {
+		d.scanner.Buffer(d.buf, d.lineLimit+d.terminatorLength())
+	}
+}
+
+// terminatorLength returns the length of the longest line terminator.
+func (d *Decoder) terminatorLength() int {
+	if d.recordLength > 0 {
+		return 0
+	}
+	n := len(d.lineTerminator)
+	for _, t := range d.lineTerminators {
+		if len(t) > n {
+			n = len(t)
+		}
+	}
+	return n
+}
+
+// scanError adds the line number to an error of the scanner. The line that could not
+// be read follows the current line and any lines read ahead of it.
+func (d *Decoder) scanError(err error) error {
+	if !errors.Is(err, ErrTooLong) {
+		return err
+	}
+	max := d.lineLimit
+	if max <= 0 {
+		max = bufio.MaxScanTokenSize - d.terminatorLength()
+	}
+	return &LineTooLongError{Line: d.lineNum + len(d.ahead) + 1, Max: max}
+}
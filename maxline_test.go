@@ -0,0 +1,148 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_SetMaxLineLength(t *testing.T) {
+	type S struct {
+		ID   string `fixed:"1,3"`
+		Name string `fixed:"4,8"`
+	}
+
+	for _, tt := range []struct {
+		name    string
+		in      string
+		setup   func(d *Decoder)
+		want    []S
+		wantErr *LineTooLongError
+	}{
+		{
+			name:  "within limit",
+			in:    "001Ann  \n002Bob  ",
+			setup: func(d *Decoder) { d.SetMaxLineLength(8) },
+			want:  []S{{"001", "Ann"}, {"002", "Bob"}},
+		},
+		{
+			name:    "terminated line too long",
+			in:      "001Ann  \n002Bob   \n003Cy",
+			setup:   func(d *Decoder) { d.SetMaxLineLength(8) },
+			want:    []S{{"001", "Ann"}},
+			wantErr: &LineTooLongError{Line: 2, Max: 8},
+		},
+		{
+			name:    "final line too long",
+			in:      "001Ann  \n002Bob   ",
+			setup:   func(d *Decoder) { d.SetMaxLineLength(8) },
+			want:    []S{{"001", "Ann"}},
+			wantErr: &LineTooLongError{Line: 2, Max: 8},
+		},
+		{
+			name:    "no terminator",
+			in:      strings.Repeat("a", 1000),
+			setup:   func(d *Decoder) { d.SetMaxLineLength(8) },
+			wantErr: &LineTooLongError{Line: 1, Max: 8},
+		},
+		{
+			name: "universal newlines",
+			in:   "001Ann  \r\n002Bob  \r003Cy   \n",
+			setup: func(d *Decoder) {
+				d.SetMaxLineLength(8)
+				d.SetUniversalNewlines(true)
+			},
+			want: []S{{"001", "Ann"}, {"002", "Bob"}, {"003", "Cy"}},
+		},
+		{
+			name: "trailer",
+			in:   "001Ann  \n002Bob  \nTOTAL 2 LINES\n",
+			setup: func(d *Decoder) {
+				d.SetMaxLineLength(8)
+				d.SetSkipTrailer(1)
+			},
+			want:    []S{{"001", "Ann"}},
+			wantErr: &LineTooLongError{Line: 3, Max: 8},
+		},
+		{
+			name: "record length",
+			in:   "001Ann  002Bob  ",
+			setup: func(d *Decoder) {
+				d.SetMaxLineLength(8)
+				d.SetRecordLength(8)
+			},
+			want: []S{{"001", "Ann"}, {"002", "Bob"}},
+		},
+		{
+			name:  "buffer",
+			in:    "001Ann  \n002Bob  \n",
+			setup: func(d *Decoder) { d.Buffer(make([]byte, 0, 4), 8) },
+			want:  []S{{"001", "Ann"}, {"002", "Bob"}},
+		},
+		{
+			name:    "default limit",
+			in:      strings.Repeat("a", 70000),
+			wantErr: &LineTooLongError{Line: 1, Max: 65535},
+		},
+	} {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.in)
+			name := tt.name
+			if oneByte {
+				r = iotest.OneByteReader(r)
+				name += " one byte at a time"
+			}
+			t.Run(name, func(t *testing.T) {
+				d := NewDecoder(r)
+				if tt.setup != nil {
+					tt.setup(d)
+				}
+				var have []S
+				err := d.Decode(&have)
+				if tt.wantErr == nil {
+					if err != nil {
+						t.Fatalf("Decode() unexpected error: %v", err)
+					}
+				} else {
+					var tooLong *LineTooLongError
+					if !errors.Is(err, ErrTooLong) || !errors.As(err, &tooLong) || *tooLong != *tt.wantErr {
+						t.Fatalf("Decode() want error %v, have %v", tt.wantErr, err)
+					}
+				}
+				if !reflect.DeepEqual(have, tt.want) {
+					t.Errorf("Decode() want %q, have %q", tt.want, have)
+				}
+			})
+		}
+	}
+
+	t.Run("raised limit", func(t *testing.T) {
+		var s struct {
+			Last string `fixed:"99999,100000"`
+		}
+		line := append(bytes.Repeat([]byte(" "), 99998), "ok\n"...)
+		d := NewDecoder(bytes.NewReader(line))
+		d.SetMaxLineLength(100000)
+		if err := d.Decode(&s); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if s.Last != "ok" {
+			t.Errorf("Decode() want %q, have %q", "ok", s.Last)
+		}
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(strings.Repeat("001Ann  \n", 300) + "002Bob   \n"))
+		d.SetMaxLineLength(8)
+		d.SetConcurrency(2)
+		var have []S
+		var tooLong *LineTooLongError
+		if err := d.Decode(&have); !errors.As(err, &tooLong) || tooLong.Line != 301 {
+			t.Fatalf("Decode() want error on line 301, have %v", err)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package fixedwidth
+
+import "reflect"
+
+// LineNumber holds the line of the input, starting at 1, that a struct was decoded
+// from.
+//
+// If a struct has an exported field of type LineNumber without a `fixed` tag, the
+// Decoder stores the line number in it, counting skipped lines. This also holds for
+// types that implement Unmarshaler and for slices decoded by Unmarshal. The field is
+// ignored by the Encoder.
+type LineNumber int
+
+// LineOffset holds the offset in bytes of the start of the line a struct was decoded
+// from. It is set by the Decoder in the same way as LineNumber.
+type LineOffset int64
+
+// LineTerminator holds the terminator of the line a struct was decoded from, or nil if
+// the line was not terminated, such as the last line of an input without a final
+// terminator or a record read with SetRecordLength or a RecordReader. It is set by the
+// Decoder in the same way as LineNumber.
+//
+// An Encoder configured with SetUseLineTerminatorFields writes it after the line.
+type LineTerminator []byte
+
+var (
+	lineNumberType     = reflect.TypeOf(LineNumber(0))
+	lineOffsetType     = reflect.TypeOf(LineOffset(0))
+	lineTerminatorType = reflect.TypeOf(LineTerminator(nil))
+)
+
+// isMetadataField reports whether f is an untagged, exported field of type t.
+func isMetadataField(f reflect.StructField, t reflect.Type) bool {
+	return f.Type == t && f.PkgPath == "" && f.Tag.Get("fixed") == ""
+}
+
+// lineMeta is the position of a line in the input, which is stored in the metadata
+// fields of the struct the line is decoded into. terminator is shared with the
+// configuration of the Decoder.
+type lineMeta struct {
+	lineNum    int
+	offset     int64
+	terminator []byte
+}
+
+// lineMeta returns the position of line, the line that was read last.
+func (d *Decoder) lineMeta(line []byte) lineMeta {
+	return lineMeta{lineNum: d.lineNum, offset: d.lineOffset(line), terminator: d.terminator}
+}
+
+// A lineSetter decodes a whole line into v, like a valueSetter, and stores meta in the
+// metadata fields of the struct v holds.
+type lineSetter func(v reflect.Value, raw rawBytes, meta lineMeta) error
+
+// withLineMetadata returns a lineSetter that decodes a line into a value of type t using
+// set. The metadata is stored separately from the line, so that it is not carried
+// along while the fields of the line are decoded.
+func withLineMetadata(t reflect.Type, set valueSetter) lineSetter {
+	st, depth := t, 0
+	for st.Kind() == reflect.Ptr {
+		st = st.Elem()
+		depth++
+	}
+	switch st.Kind() {
+	case reflect.Interface:
+		return func(v reflect.Value, raw rawBytes, meta lineMeta) error {
+			if err := set(v, raw); err != nil {
+				return err
+			}
+			for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+				if v.IsNil() {
+					return nil
+				}
+				v = v.Elem()
+			}
+			if v.Kind() == reflect.Struct {
+				setLineMetadata(v, cachedStructSpec(v.Type()), meta)
+			}
+			return nil
+		}
+	case reflect.Struct:
+		if spec := cachedStructSpec(st); spec.hasLineMetadata() {
+			return func(v reflect.Value, raw rawBytes, meta lineMeta) error {
+				if err := set(v, raw); err != nil {
+					return err
+				}
+				for i := 0; i < depth; i++ {
+					if v.IsNil() {
+						return nil
+					}
+					v = v.Elem()
+				}
+				setLineMetadata(v, spec, meta)
+				return nil
+			}
+		}
+	}
+	return func(v reflect.Value, raw rawBytes, _ lineMeta) error {
+		return set(v, raw)
+	}
+}
+
+// hasLineMetadata reports whether the struct has a field that holds line metadata.
+func (ss structSpec) hasLineMetadata() bool {
+	return ss.lineNumber >= 0 || ss.lineOffset >= 0 || ss.lineTerminator >= 0
+}
+
+// setLineMetadata stores meta in the fields of the struct v that hold it.
+func setLineMetadata(v reflect.Value, ss structSpec, meta lineMeta) {
+	if ss.lineNumber >= 0 {
+		v.Field(ss.lineNumber).SetInt(int64(meta.lineNum))
+	}
+	if ss.lineOffset >= 0 {
+		v.Field(ss.lineOffset).SetInt(meta.offset)
+	}
+	if ss.lineTerminator >= 0 {
+		// The terminator is shared with the configuration of the Decoder.
+		v.Field(ss.lineTerminator).SetBytes(append([]byte(nil), meta.terminator...))
+	}
+}
+
+// lineTerminatorField returns the value of the LineTerminator field of v, which is a
+// struct or a pointer to one, or nil if v has no such field.
+func lineTerminatorField(v reflect.Value) []byte {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	ss := cachedStructSpec(v.Type())
+	if ss.lineTerminator < 0 {
+		return nil
+	}
+	return v.Field(ss.lineTerminator).Bytes()
+}
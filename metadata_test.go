@@ -0,0 +1,171 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_LineMetadata(t *testing.T) {
+	type S struct {
+		ID     string `fixed:"1,3"`
+		Line   LineNumber
+		Offset LineOffset
+		Raw    RawLine
+		Term   LineTerminator
+	}
+
+	const in = "\xef\xbb\xbfHDR\r\n001 \r\n# x\n002\n003"
+	want := []S{
+		{"001", 2, 8, RawLine("001 "), LineTerminator("\r\n")},
+		{"002", 4, 18, RawLine("002"), LineTerminator("\n")},
+		{"003", 5, 22, RawLine("003"), nil},
+	}
+
+	for _, concurrency := range []int{0, 2} {
+		d := NewDecoder(strings.NewReader(in))
+		d.SetConcurrency(concurrency)
+		d.SetUniversalNewlines(true)
+		d.SetSkipHeader(1)
+		d.SetCommentPrefix("#")
+		var have []S
+		if err := d.Decode(&have); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("Decode() with concurrency %d want %+v, have %+v", concurrency, want, have)
+		}
+	}
+
+	t.Run("each", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("001\r\n002\n"))
+		d.SetUniversalNewlines(true)
+		var have []LineTerminator
+		err := NewReader[S](d).Each(func(s S) error {
+			have = append(have, s.Term)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Each() unexpected error: %v", err)
+		}
+		if want := []LineTerminator{LineTerminator("\r\n"), LineTerminator("\n")}; !reflect.DeepEqual(have, want) {
+			t.Errorf("Each() want terminators %q, have %q", want, have)
+		}
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		var have []*S
+		if err := Unmarshal([]byte("001\n\n002"), &have); err != nil {
+			t.Fatalf("Unmarshal() unexpected error: %v", err)
+		}
+		if len(have) != 3 || have[0].Line != 1 || have[1] != nil || have[2].Line != 3 || have[2].Offset != 5 {
+			t.Errorf("Unmarshal() want lines 1, nil, and 3 at offset 5, have %+v", have)
+		}
+	})
+
+	t.Run("record reader", func(t *testing.T) {
+		r := NewRecordReaderBytes([]byte("001\n002\n003\n"), 3, 1)
+		var s S
+		if err := r.ReadRecord(2, &s); err != nil {
+			t.Fatalf("ReadRecord() unexpected error: %v", err)
+		}
+		if s.Line != 3 || s.Offset != 8 {
+			t.Errorf("ReadRecord() want line 3 at offset 8, have %+v", s)
+		}
+	})
+
+	t.Run("raw record", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("001\n002\n"))
+		var s S
+		if err := d.Decode(&s); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		r, err := d.DecodeRaw(S{})
+		if err != nil {
+			t.Fatalf("DecodeRaw() unexpected error: %v", err)
+		}
+		if err := r.DecodeInto(&s); err != nil {
+			t.Fatalf("DecodeInto() unexpected error: %v", err)
+		}
+		if s.Line != 2 || s.Offset != 4 {
+			t.Errorf("DecodeInto() want line 2 at offset 4, have %+v", s)
+		}
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		b, err := Marshal(S{ID: "001", Line: 7, Offset: 42})
+		if err != nil {
+			t.Fatalf("Marshal() unexpected error: %v", err)
+		}
+		if string(b) != "001" {
+			t.Errorf("Marshal() want %q, have %q", "001", b)
+		}
+	})
+}
+
+func TestEncoder_SetUseLineTerminatorFields(t *testing.T) {
+	type S struct {
+		ID   string `fixed:"1,3"`
+		Term LineTerminator
+	}
+
+	for _, tt := range []struct {
+		name string
+		in   string
+	}{
+		{name: "mixed terminators", in: "001\r\n002\n003\r"},
+		{name: "unterminated last line", in: "001\r\n002"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.in))
+			d.SetUniversalNewlines(true)
+			var v []S
+			if err := d.Decode(&v); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.SetUseLineTerminatorFields(true)
+			if err := e.Encode(v); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if buf.String() != tt.in {
+				t.Errorf("Encode() want %q, have %q", tt.in, buf.String())
+			}
+
+			buf.Reset()
+			for _, s := range v {
+				if err := e.Encode(&s); err != nil {
+					t.Fatalf("Encode() unexpected error: %v", err)
+				}
+			}
+			if buf.String() != tt.in {
+				t.Errorf("Encode() of each value want %q, have %q", tt.in, buf.String())
+			}
+		})
+	}
+
+	t.Run("unset", func(t *testing.T) {
+		var buf bytes.Buffer
+		e := NewEncoder(&buf)
+		e.SetUseLineTerminatorFields(true)
+		if err := e.Encode([]S{{ID: "001"}, {ID: "002", Term: LineTerminator("\r\n")}, {ID: "003"}}); err != nil {
+			t.Fatalf("Encode() unexpected error: %v", err)
+		}
+		if want := "001\n002\r\n003"; buf.String() != want {
+			t.Errorf("Encode() want %q, have %q", want, buf.String())
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		b, err := Marshal([]S{{ID: "001", Term: LineTerminator("\r\n")}, {ID: "002", Term: LineTerminator("\r\n")}})
+		if err != nil {
+			t.Fatalf("Marshal() unexpected error: %v", err)
+		}
+		if want := "001\n002"; string(b) != want {
+			t.Errorf("Marshal() want %q, have %q", want, b)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package fixedwidth
+
+import (
+	"reflect"
+	"sync"
+)
+
+// parallelBatchSize is the number of lines decoded by a goroutine at a time.
+const parallelBatchSize = 256
+
+// A lineBatch is a group of consecutive lines decoded by a single goroutine.
+type lineBatch struct {
+	lines [][]byte
+
+	// metas holds the position of each line. Line numbers are not contiguous if lines
+	// were skipped.
+	metas []lineMeta
+
+	// buf holds the data of lines, which are copied out of the scanner's buffer.
+	buf []byte
+
+	// Set by the worker that decodes the batch. values holds the lines that were
+	// decoded before err occurred.
+	values reflect.Value
+	err    error
+
+	// scanErr is an error that occurred while reading the line after the batch.
+	scanErr error
+	done    chan struct{}
+}
+
+// decodeParallel decodes the remaining lines of the input into values of type t using
+// d.concurrency goroutines. emit is called with each value in input order.
+//
+// One goroutine reads batches of lines from the input while the workers decode them.
+// At most 2*d.concurrency batches are held in memory at a time.
+//
+// If an error occurs, emit is called for every line before the line that failed and
+// the error is returned.
+func (d *Decoder) decodeParallel(t reflect.Type, emit func(v reflect.Value) error) error {
+	workers := d.concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	setter := d.valueSetter(t)
+
+	var (
+		jobs    = make(chan *lineBatch)
+		pending = make(chan *lineBatch, 2*workers)
+		stop    = make(chan struct{})
+		read    = make(chan struct{})
+	)
+
+	// The reader changes the position of the Decoder while values are emitted, so the
+	// position after the value emitted last is tracked here and reported instead.
+	d.emitted = &Checkpoint{Line: d.lineNum, Offset: d.offset, Terminator: d.terminator}
+	defer func() { d.emitted = nil }()
+
+	// Read batches of lines. Every batch is queued in input order before it is
+	// handed to a worker, which keeps the output ordered.
+	go func() {
+		defer close(read)
+		defer close(pending)
+		defer close(jobs)
+		var bufSize int
+		for {
+			b := &lineBatch{
+				lines: make([][]byte, 0, parallelBatchSize),
+				metas: make([]lineMeta, 0, parallelBatchSize),
+				buf:   make([]byte, 0, bufSize),
+				done:  make(chan struct{}),
+			}
+			for len(b.lines) < parallelBatchSize {
+				if err := d.checkContext(); err != nil {
+					b.scanErr = err
+					break
+				}
+				line, ok, err := d.nextLine()
+				if !ok {
+					b.scanErr = err
+					break
+				}
+				n := len(b.buf)
+				b.buf = append(b.buf, line...)
+				b.lines = append(b.lines, b.buf[n:len(b.buf):len(b.buf)])
+				b.metas = append(b.metas, d.lineMeta(line))
+			}
+			if len(b.lines) == 0 && b.scanErr == nil {
+				return
+			}
+			// Lines tend to be of similar length, so size the next buffer after this one.
+			bufSize = len(b.buf)
+
+			select {
+			case pending <- b:
+			case <-stop:
+				return
+			}
+			select {
+			case jobs <- b:
+			case <-stop:
+				return
+			}
+			if b.scanErr != nil || d.done {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for b := range jobs {
+				d.decodeBatch(b, t, setter)
+				close(b.done)
+			}
+		}()
+	}
+
+	err := func() error {
+		for b := range pending {
+			<-b.done
+			for i := 0; i < b.values.Len(); i++ {
+				m := b.metas[i]
+				*d.emitted = Checkpoint{
+					Line:       m.lineNum,
+					Offset:     m.offset + int64(len(b.lines[i])+len(m.terminator)),
+					Terminator: m.terminator,
+				}
+				if err := emit(b.values.Index(i)); err != nil {
+					return err
+				}
+			}
+			if b.err != nil {
+				return b.err
+			}
+			if b.scanErr != nil {
+				return b.scanErr
+			}
+		}
+		return nil
+	}()
+
+	// Wait for the reader to stop before returning so that the decoder is not in use
+	// by another goroutine once the caller regains control of it.
+	close(stop)
+	<-read
+	wg.Wait()
+	return err
+}
+
+// decodeBatch decodes the lines of b into b.values.
+func (d *Decoder) decodeBatch(b *lineBatch, t reflect.Type, setter lineSetter) {
+	values := reflect.MakeSlice(reflect.SliceOf(t), len(b.lines), len(b.lines))
+	for i, line := range b.lines {
+		if err := d.decodeLine(values.Index(i), setter, line, b.metas[i]); err != nil {
+			b.values = values.Slice(0, i)
+			b.err = err
+			return
+		}
+	}
+	b.values = values
+}
@@ -0,0 +1,81 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_SetConcurrency(t *testing.T) {
+	type S struct {
+		ID   int    `fixed:"1,6"`
+		Name string `fixed:"7,12"`
+	}
+
+	var (
+		buf  bytes.Buffer
+		want []S
+	)
+	for i := 0; i < 2000; i++ {
+		s := S{i, "n" + strconv.Itoa(i%100)}
+		want = append(want, s)
+		b, _ := Marshal(s)
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+
+	for _, n := range []int{0, 1, 2, 8} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			dec := NewDecoder(bytes.NewReader(buf.Bytes()))
+			dec.SetConcurrency(n)
+			var have []S
+			if err := dec.Decode(&have); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("Decode() decoded %d values, want %d in order", len(have), len(want))
+			}
+			var s S
+			if err := dec.Decode(&s); err == nil {
+				t.Errorf("Decode() expected io.EOF after decoding all lines")
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+		lines[1500] = "xxxxxxfoo   "
+		dec := NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+		dec.SetConcurrency(4)
+
+		var have []S
+		err := dec.Decode(&have)
+		var typeErr *UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			t.Fatalf("Decode() want *UnmarshalTypeError, have %v", err)
+		}
+		if typeErr.Line != 1501 {
+			t.Errorf("Decode() error line want 1501, have %d", typeErr.Line)
+		}
+		if !strings.HasPrefix(err.Error(), "fixedwidth: line 1501: cannot unmarshal") {
+			t.Errorf("Decode() unexpected error message %q", err.Error())
+		}
+		if len(have) != 1500 || !reflect.DeepEqual(have, want[:1500]) {
+			t.Errorf("Decode() want the 1500 lines before the error, have %d", len(have))
+		}
+	})
+}
+
+func TestUnmarshalTypeError_Line(t *testing.T) {
+	var v []struct {
+		I int `fixed:"1,3"`
+	}
+	err := Unmarshal([]byte("1\n2\nfoo\n4"), &v)
+	var typeErr *UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Line != 3 {
+		t.Errorf("Unmarshal() want error on line 3, have %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package fixedwidth
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// A RecordPatcher rewrites fields of existing records in fixed-width data in which every
+// record has the same length in bytes, such as a file opened for writing. Records are
+// laid out as for a RecordReader. Only the bytes of the rewritten fields are written, so
+// the rest of each record is left untouched.
+//
+// The positions in the `fixed` struct tags are always byte positions. Data whose
+// indices are expressed in codepoints cannot be patched, as a field does not start at
+// the same byte in every record.
+type RecordPatcher struct {
+	w    io.WriterAt
+	size int64
+
+	recordLength     int
+	terminatorLength int
+}
+
+// NewRecordPatcher returns a RecordPatcher that writes to the records of recordLength
+// bytes in the first size bytes of w. Records are separated by terminatorLength bytes.
+//
+// To patch an *os.File, open it for writing and pass the size reported by its Stat
+// method.
+func NewRecordPatcher(w io.WriterAt, size int64, recordLength, terminatorLength int) *RecordPatcher {
+	return &RecordPatcher{
+		w:                w,
+		size:             size,
+		recordLength:     recordLength,
+		terminatorLength: terminatorLength,
+	}
+}
+
+// Len returns the number of records in the data. A short final record is not counted.
+func (p *RecordPatcher) Len() int {
+	return recordCount(p.size, p.recordLength, p.terminatorLength)
+}
+
+// PatchRecord encodes the named fields of v, a struct or a pointer to one, and writes
+// them to the record with index i. Each field is encoded using its struct tag and
+// always overwrites all of its columns: a value that is shorter than its field is
+// aligned and padded as the tag specifies, so masking a 9-byte field with "XXX" leaves
+// "XXX" followed by 6 spaces, and a value that is longer than its field is an error
+// instead of being truncated. Every field is encoded before any is written.
+//
+// It returns ErrRecordIndex if i is not less than Len.
+func (p *RecordPatcher) PatchRecord(i int, v interface{}, fields ...string) error {
+	if i < 0 || i >= p.Len() {
+		return ErrRecordIndex
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return errors.New("fixedwidth: PatchRecord requires a struct")
+	}
+	if len(fields) == 0 {
+		return errors.New("fixedwidth: PatchRecord requires at least one field")
+	}
+
+	t := rv.Type()
+	ss := cachedStructSpec(t)
+	specs := make([]fieldSpec, len(fields))
+	values := make([][]byte, len(fields))
+	for j, name := range fields {
+		sf, ok := t.FieldByName(name)
+		if !ok || len(sf.Index) != 1 || !ss.fieldSpecs[sf.Index[0]].ok {
+			return errors.New("fixedwidth: unknown field " + t.Name() + "." + name)
+		}
+		spec := ss.fieldSpecs[sf.Index[0]]
+		if spec.endPos > p.recordLength {
+			return errors.New("fixedwidth: field " + t.Name() + "." + name + " is outside of the record")
+		}
+
+		fv := rv.Field(sf.Index[0])
+		value, err := spec.encoder(fv)
+		if err != nil {
+			return err
+		}
+		if len(value.data) > spec.len() {
+			return errors.New("fixedwidth: value of " + t.Name() + "." + name + " is longer than its field")
+		}
+
+		// Encode the field at the start of a line of its own length, so that it is
+		// padded in the same way as by the Encoder.
+		b := newLineBuilder(spec.len(), spec.len(), ' ')
+		col := spec
+		col.startPos, col.endPos = 1, spec.len()
+		if err := spec.encoder.Write(b, fv, col); err != nil {
+			return err
+		}
+		specs[j], values[j] = spec, b.data
+	}
+
+	offset := int64(i) * int64(p.recordLength+p.terminatorLength)
+	for j, spec := range specs {
+		if _, err := p.w.WriteAt(values[j], offset+int64(spec.startPos-1)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package fixedwidth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordPatcher(t *testing.T) {
+	type S struct {
+		ID       int    `fixed:"1,3,right,0"`
+		SSN      string `fixed:"4,12"`
+		Status   string `fixed:"13,13"`
+		Note     string `fixed:"14,17"`
+		Flag     string `fixed:"14,17,left,*"`
+		Beyond   string `fixed:"17,20"`
+		Untagged string
+	}
+
+	const data = "001123456789fabcd\n002987654321fefgh\n003111223333f    "
+
+	for _, tt := range []struct {
+		name    string
+		i       int
+		v       interface{}
+		fields  []string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "single field",
+			i:      1,
+			v:      S{Status: "t"},
+			fields: []string{"Status"},
+			want:   "001123456789fabcd\n002987654321tefgh\n003111223333f    ",
+		},
+		{
+			name:   "multiple fields and pointer",
+			i:      2,
+			v:      &S{SSN: "XXXXXXXXX", ID: 7},
+			fields: []string{"SSN", "ID"},
+			want:   "001123456789fabcd\n002987654321fefgh\n007XXXXXXXXXf    ",
+		},
+		{
+			name:   "short value is padded",
+			i:      0,
+			v:      S{Note: "x", Flag: "y"},
+			fields: []string{"Note"},
+			want:   "001123456789fx   \n002987654321fefgh\n003111223333f    ",
+		},
+		{
+			name:   "short value replaces the whole field",
+			i:      1,
+			v:      S{SSN: "XXX"},
+			fields: []string{"SSN"},
+			want:   "001123456789fabcd\n002XXX      fefgh\n003111223333f    ",
+		},
+		{
+			name:   "pad character",
+			i:      0,
+			v:      S{Flag: "y"},
+			fields: []string{"Flag"},
+			want:   "001123456789fy***\n002987654321fefgh\n003111223333f    ",
+		},
+		{name: "too long", i: 0, v: S{Note: "toolong"}, fields: []string{"Note"}, wantErr: "fixedwidth: value of S.Note is longer than its field"},
+		{name: "too long is not partially written", i: 0, v: S{ID: 1, Note: "toolong"}, fields: []string{"ID", "Note"}, wantErr: "fixedwidth: value of S.Note is longer than its field"},
+		{name: "outside of record", i: 0, v: S{}, fields: []string{"Beyond"}, wantErr: "fixedwidth: field S.Beyond is outside of the record"},
+		{name: "untagged field", i: 0, v: S{}, fields: []string{"Untagged"}, wantErr: "fixedwidth: unknown field S.Untagged"},
+		{name: "no fields", i: 0, v: S{}, wantErr: "fixedwidth: PatchRecord requires at least one field"},
+		{name: "not a struct", i: 0, v: 1, fields: []string{"ID"}, wantErr: "fixedwidth: PatchRecord requires a struct"},
+		{name: "negative index", i: -1, v: S{}, fields: []string{"ID"}, wantErr: ErrRecordIndex.Error()},
+		{name: "index out of range", i: 3, v: S{}, fields: []string{"ID"}, wantErr: ErrRecordIndex.Error()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "records")
+			if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.OpenFile(path, os.O_RDWR, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			p := NewRecordPatcher(f, int64(len(data)), 17, 1)
+			if p.Len() != 3 {
+				t.Fatalf("Len() want 3, have %d", p.Len())
+			}
+			err = p.PatchRecord(tt.i, tt.v, tt.fields...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("PatchRecord() want error %q, have %v", tt.wantErr, err)
+				}
+				tt.want = data
+			} else if err != nil {
+				t.Fatalf("PatchRecord() unexpected error: %v", err)
+			}
+
+			have, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(have) != tt.want {
+				t.Errorf("PatchRecord() want\n%q\nhave\n%q", tt.want, have)
+			}
+		})
+	}
+}
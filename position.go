@@ -0,0 +1,35 @@
+package fixedwidth
+
+// LineNumber returns the line of the input, starting at 1, that was read last. Skipped
+// lines are counted, so after a call to Decode with a pointer to a struct it is the line
+// the value was decoded from. It is 0 before the first line has been read.
+//
+// While Reader.Each decodes lines concurrently, LineNumber and the other position
+// methods may be called from its callback and report the value passed to it, although
+// the Decoder has read further ahead. Once Each has returned, they report the line the
+// Decoder read last, so take a Checkpoint in the callback to resume after it.
+func (d *Decoder) LineNumber() int {
+	if d.emitted != nil {
+		return d.emitted.Line
+	}
+	return d.lineNum
+}
+
+// InputOffset returns the offset in bytes of the end of the line that was read last,
+// including its terminator. This is where the next line starts, for example to resume
+// decoding after it or to report progress. A byte order mark stripped from the start of
+// the input is counted.
+//
+// Like LineNumber, the offset is that of the last line Decode returned, even if the
+// Decoder has read further ahead to recognize trailer lines.
+func (d *Decoder) InputOffset() int64 {
+	if d.emitted != nil {
+		return d.emitted.Offset
+	}
+	return d.offset
+}
+
+// lineOffset returns the offset of the start of line, the line that was read last.
+func (d *Decoder) lineOffset(line []byte) int64 {
+	return d.offset - int64(len(line)+len(d.terminator))
+}
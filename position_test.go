@@ -0,0 +1,148 @@
+package fixedwidth
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_Position(t *testing.T) {
+	type S struct {
+		ID string `fixed:"1,3"`
+	}
+
+	type position struct {
+		Line   int
+		Offset int64
+	}
+
+	for _, tt := range []struct {
+		name  string
+		in    string
+		setup func(d *Decoder)
+		want  []position
+	}{
+		{
+			name: "lines",
+			in:   "001\n002\n003",
+			want: []position{{1, 4}, {2, 8}, {3, 11}},
+		},
+		{
+			name: "skipped lines",
+			in:   "HDR\n001\n# comment\n002\n",
+			setup: func(d *Decoder) {
+				d.SetSkipHeader(1)
+				d.SetCommentPrefix("#")
+			},
+			want: []position{{2, 8}, {4, 22}},
+		},
+		{
+			name:  "trailer",
+			in:    "001\n002\nTRL\n",
+			setup: func(d *Decoder) { d.SetSkipTrailer(1) },
+			want:  []position{{1, 4}, {2, 8}},
+		},
+		{
+			name:  "mixed terminators",
+			in:    "001\r\n002\n003\r",
+			setup: func(d *Decoder) { d.SetUniversalNewlines(true) },
+			want:  []position{{1, 5}, {2, 9}, {3, 13}},
+		},
+		{
+			name:  "record length",
+			in:    "001002",
+			setup: func(d *Decoder) { d.SetRecordLength(3) },
+			want:  []position{{1, 3}, {2, 6}},
+		},
+		{
+			name: "byte order mark",
+			in:   "\xef\xbb\xbf001\n002\n",
+			want: []position{{1, 7}, {2, 11}},
+		},
+	} {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.in)
+			name := tt.name
+			if oneByte {
+				r = iotest.OneByteReader(r)
+				name += " one byte at a time"
+			}
+			t.Run(name, func(t *testing.T) {
+				d := NewDecoder(r)
+				if tt.setup != nil {
+					tt.setup(d)
+				}
+				if d.LineNumber() != 0 || d.InputOffset() != 0 {
+					t.Fatalf("before Decode() want position 0, have %d, %d", d.LineNumber(), d.InputOffset())
+				}
+				var have []position
+				for {
+					var s S
+					err := d.Decode(&s)
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("Decode() unexpected error: %v", err)
+					}
+					have = append(have, position{d.LineNumber(), d.InputOffset()})
+				}
+				if !reflect.DeepEqual(have, tt.want) {
+					t.Errorf("Decode() want positions %v, have %v", tt.want, have)
+				}
+			})
+		}
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		const in = "001\n002\n003\n"
+		d := NewDecoder(strings.NewReader(in))
+		var s S
+		if err := d.Decode(&s); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		d = NewDecoder(strings.NewReader(in[d.InputOffset():]))
+		var rest []S
+		if err := d.Decode(&rest); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if want := []S{{"002"}, {"003"}}; !reflect.DeepEqual(rest, want) {
+			t.Errorf("Decode() after resuming want %v, have %v", want, rest)
+		}
+	})
+
+	t.Run("slice", func(t *testing.T) {
+		const in = "001\n002\n"
+		d := NewDecoder(strings.NewReader(in))
+		d.SetConcurrency(2)
+		var s []S
+		if err := d.Decode(&s); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if d.LineNumber() != 2 || d.InputOffset() != int64(len(in)) {
+			t.Errorf("Decode() want position 2, %d, have %d, %d", len(in), d.LineNumber(), d.InputOffset())
+		}
+	})
+	t.Run("concurrent each", func(t *testing.T) {
+		in := strings.Repeat("001\n002\r\n", 500)
+		positions := func(concurrency int) []position {
+			d := NewDecoder(strings.NewReader(in))
+			d.SetUniversalNewlines(true)
+			d.SetConcurrency(concurrency)
+			var have []position
+			err := NewReader[S](d).Each(func(S) error {
+				have = append(have, position{d.LineNumber(), d.InputOffset()})
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Each() unexpected error: %v", err)
+			}
+			return have
+		}
+		if want, have := positions(0), positions(4); !reflect.DeepEqual(have, want) {
+			t.Errorf("Each() with concurrency reported positions that differ from those without")
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package fixedwidth
+
+import "reflect"
+
+// SetFields configures the Decoder to only decode the named fields of structs. Other
+// fields are skipped without being sliced or parsed and keep their current value, which
+// is the zero value when decoding into a new value. Only the top-level fields of the
+// decoded struct are filtered; a selected field holding a nested struct is decoded in
+// full.
+//
+// Calling SetFields without names restores the default behavior of decoding every field.
+// While fields are filtered, Unmarshaler implementations such as those generated by
+// fixedwidth-gen are not used, as they always decode every field.
+func (d *Decoder) SetFields(names ...string) {
+	if len(names) == 0 {
+		d.SetFieldFilter(nil)
+		return
+	}
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[name] = true
+	}
+	d.SetFieldFilter(func(name string) bool { return set[name] })
+}
+
+// SetFieldFilter is like SetFields but decodes the fields for which keep returns true.
+// keep is called once for each field of a struct type, not for every line. A nil keep
+// restores the default behavior of decoding every field.
+func (d *Decoder) SetFieldFilter(keep func(name string) bool) {
+	d.fieldFilter = keep
+	d.lastType = nil
+}
+
+// projectedSetter returns the valueSetter for t that only decodes the struct fields
+// accepted by the field filter.
+func (d *Decoder) projectedSetter(t reflect.Type) valueSetter {
+	switch {
+	case t == recordPtrType || t.Implements(textUnmarshalerType) || reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return newValueSetter(t)
+	case t.Kind() == reflect.Ptr:
+		return ptrSetterWith(t, d.projectedSetter(t.Elem()))
+	case t.Kind() == reflect.Struct:
+		keep := make([]bool, t.NumField())
+		for i := range keep {
+			keep[i] = d.fieldFilter(t.Field(i).Name)
+		}
+		return projectedStructSetter(t, keep)
+	}
+	return newValueSetter(t)
+}
@@ -0,0 +1,107 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// projected implements Unmarshaler, which is bypassed while fields are filtered.
+type projected struct {
+	ID   int    `fixed:"1,3"`
+	Name string `fixed:"4,8"`
+}
+
+func (p *projected) UnmarshalFixedWidth(data []byte) error {
+	p.Name = "unmarshaler"
+	return nil
+}
+
+func TestDecoder_SetFields(t *testing.T) {
+	type nested struct {
+		A string `fixed:"1,1"`
+		B string `fixed:"2,2"`
+	}
+	type S struct {
+		ID     int    `fixed:"1,3"`
+		Name   string `fixed:"4,8"`
+		Grade  int    `fixed:"9,11"`
+		Nested nested `fixed:"12,13"`
+	}
+
+	// The Grade column is not a number, which is only an error if it is decoded.
+	const input = "  1Ann  abcxy\n  2Bob  defzw\n"
+
+	for _, tt := range []struct {
+		name        string
+		setup       func(d *Decoder)
+		want        []S
+		concurrency int
+	}{
+		{
+			name:  "names",
+			setup: func(d *Decoder) { d.SetFields("Name", "Nested") },
+			want:  []S{{Name: "Ann", Nested: nested{"x", "y"}}, {Name: "Bob", Nested: nested{"z", "w"}}},
+		},
+		{
+			name:  "filter",
+			setup: func(d *Decoder) { d.SetFieldFilter(func(name string) bool { return name == "ID" }) },
+			want:  []S{{ID: 1}, {ID: 2}},
+		},
+		{
+			name:        "concurrent",
+			setup:       func(d *Decoder) { d.SetFields("ID", "Name") },
+			want:        []S{{ID: 1, Name: "Ann"}, {ID: 2, Name: "Bob"}},
+			concurrency: 2,
+		},
+		{
+			name:  "unknown names",
+			setup: func(d *Decoder) { d.SetFields("Missing") },
+			want:  []S{{}, {}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(input))
+			d.SetConcurrency(tt.concurrency)
+			tt.setup(d)
+			var have []S
+			if err := d.Decode(&have); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(have, tt.want) {
+				t.Errorf("Decode() want %+v, have %+v", tt.want, have)
+			}
+		})
+	}
+
+	t.Run("pointer and reset", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(input))
+		d.SetFields("ID")
+		var p *S
+		if err := d.Decode(&p); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if want := (S{ID: 1}); p == nil || *p != want {
+			t.Errorf("Decode() want %+v, have %+v", want, p)
+		}
+
+		d.SetFields()
+		var s S
+		if err := d.Decode(&s); err == nil {
+			t.Errorf("Decode() want error for Grade after the filter is removed, have %+v", s)
+		}
+	})
+
+	t.Run("Unmarshaler is bypassed", func(t *testing.T) {
+		d := NewDecoder(bytes.NewReader([]byte("  7Ann  \n")))
+		d.SetFields("ID")
+		var p projected
+		if err := d.Decode(&p); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if want := (projected{ID: 7}); p != want {
+			t.Errorf("Decode() want %+v, have %+v", want, p)
+		}
+	})
+}
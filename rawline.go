@@ -0,0 +1,49 @@
+package fixedwidth
+
+import (
+	"reflect"
+	"strings"
+)
+
+// RawLine holds the original line a struct was decoded from.
+//
+// If a struct has an exported field of type RawLine without a `fixed` tag, such as an
+// embedded RawLine, the Decoder stores a copy of the line in it. When the struct is
+// encoded, the Encoder starts from that line instead of a line of spaces, so columns
+// that are not covered by a tag keep their original bytes. The line keeps its original
+// length if it is longer than the struct. An empty RawLine is encoded as usual.
+//
+// Types with methods generated by fixedwidth-gen handle RawLine fields in the same way.
+type RawLine []byte
+
+var rawLineType = reflect.TypeOf(RawLine(nil))
+
+// rawLineOf returns the RawLine field of the struct v, or nil if it has none.
+func rawLineOf(v reflect.Value, ss structSpec) []byte {
+	if ss.rawLine < 0 {
+		return nil
+	}
+	return v.Field(ss.rawLine).Bytes()
+}
+
+// lineBuilderFromRawLine returns a lineBuilder that starts with raw, padded with spaces
+// to at least the line length of ss. The fields of ss are blanked, as values that are
+// shorter than their field are not always padded.
+func lineBuilderFromRawLine(raw []byte, ss structSpec, useCodepointIndices bool) (*lineBuilder, error) {
+	value, err := newRawValue(string(raw), useCodepointIndices)
+	if err != nil {
+		return nil, err
+	}
+	n := ss.ll
+	if l := value.len(); l > n {
+		n = l
+	}
+	b := newLineBuilder(n, n+value.byteLen()-value.len(), ' ')
+	b.WriteValue(0, value)
+	for _, spec := range ss.fieldSpecs {
+		if spec.ok {
+			b.WriteASCII(spec.startPos-1, strings.Repeat(" ", spec.len()))
+		}
+	}
+	return b, nil
+}
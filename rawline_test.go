@@ -0,0 +1,85 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRawLine(t *testing.T) {
+	type account struct {
+		RawLine
+		ID     int    `fixed:"1,5,right,0"`
+		Status string `fixed:"11,12"`
+	}
+
+	for _, tt := range []struct {
+		name      string
+		codepoint bool
+		in        string
+		status    string
+		want      string
+	}{
+		{"unmapped columns", false, "00042keep-OKtrailer\n", "NO", "00042keep-NOtrailer"},
+		{"short line", false, "00042ab\n", "NO", "00042ab   NO"},
+		{"codepoints", true, "00042ķéép-OKtrailer\n", "NO", "00042ķéép-NOtrailer"},
+		{"multibyte value", true, "00042keep-OKtrailer\n", "Ñ", "00042keep-Ñ trailer"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []account
+			d := NewDecoder(bytes.NewReader([]byte(tt.in)))
+			d.SetUseCodepointIndices(tt.codepoint)
+			if err := d.Decode(&records); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if len(records) != 1 || string(records[0].RawLine) != tt.in[:len(tt.in)-1] {
+				t.Fatalf("Decode() RawLine want %q, have %+v", tt.in[:len(tt.in)-1], records)
+			}
+
+			records[0].Status = tt.status
+			var buf bytes.Buffer
+			e := NewEncoder(&buf)
+			e.SetUseCodepointIndices(tt.codepoint)
+			if err := e.Encode(records); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Encode() want %q, have %q", tt.want, buf.String())
+			}
+		})
+	}
+
+	t.Run("lines are copied", func(t *testing.T) {
+		var records []account
+		if err := Unmarshal([]byte("00001a\n00002b\n"), &records); err != nil {
+			t.Fatalf("Unmarshal() unexpected error: %v", err)
+		}
+		want := []RawLine{RawLine("00001a"), RawLine("00002b")}
+		if have := []RawLine{records[0].RawLine, records[1].RawLine}; !reflect.DeepEqual(have, want) {
+			t.Errorf("Unmarshal() RawLine want %q, have %q", want, have)
+		}
+	})
+
+	t.Run("empty raw line", func(t *testing.T) {
+		have, err := Marshal(account{ID: 7, Status: "OK"})
+		if err != nil {
+			t.Fatalf("Marshal() unexpected error: %v", err)
+		}
+		if want := "00007     OK"; string(have) != want {
+			t.Errorf("Marshal() want %q, have %q", want, have)
+		}
+	})
+
+	t.Run("unexported fields are ignored", func(t *testing.T) {
+		var v struct {
+			Name string `fixed:"1,3"`
+			raw  RawLine
+		}
+		if err := Unmarshal([]byte("abcdef"), &v); err != nil {
+			t.Fatalf("Unmarshal() unexpected error: %v", err)
+		}
+		if v.Name != "abc" || v.raw != nil {
+			t.Errorf("Unmarshal() want Name %q and no raw line, have %+v", "abc", v)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+package fixedwidth
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/ianlopshire/go-fixedwidth/internal/parse"
+)
+
+// A RawRecord is a line read by Decoder.DecodeRaw whose fields are only sliced, trimmed,
+// and parsed when they are accessed. Fields are described by the `fixed` struct tags of
+// a struct type and are trimmed in the same way as by Decode, according to their
+// alignment, padding character, and the codepoint setting of the Decoder.
+//
+// A RawRecord holds a copy of its line and stays valid after the next call to the
+// Decoder.
+type RawRecord struct {
+	d    *Decoder
+	t    reflect.Type
+	ss   structSpec
+	line rawBytes
+	meta lineMeta
+}
+
+// DecodeRaw reads the next line from its input and returns it as a RawRecord. The fields
+// of the record are described by the struct tags of schema, which is a struct or a
+// pointer to one, such as (*Person)(nil). If there is no data remaining, DecodeRaw
+// returns io.EOF.
+func (d *Decoder) DecodeRaw(schema interface{}) (*RawRecord, error) {
+	t, err := schemaType(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	line, ok, err := d.nextLine()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, io.EOF
+	}
+	return &RawRecord{
+		d:    d,
+		t:    t,
+		ss:   cachedStructSpec(t),
+		line: newRawBytes(append([]byte(nil), line...), d.useCodepointIndices),
+		meta: d.lineMeta(line),
+	}, nil
+}
+
+// schemaType returns the struct type of schema, which is a struct or a pointer to one.
+func schemaType(schema interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(schema)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("fixedwidth: schema must be a struct")
+	}
+	return t, nil
+}
+
+// Bytes returns the line of the record, excluding its terminator. The returned slice must
+// not be modified.
+func (r *RawRecord) Bytes() []byte {
+	return r.line.data
+}
+
+// Line returns the line of the input the record was read from, starting at 1.
+func (r *RawRecord) Line() int {
+	return r.meta.lineNum
+}
+
+// Field returns the trimmed value of the named field. The returned slice aliases the
+// line of the record and must not be modified.
+func (r *RawRecord) Field(name string) ([]byte, error) {
+	raw, _, err := r.field(name)
+	return raw.data, err
+}
+
+// String returns the trimmed value of the named field as a string.
+func (r *RawRecord) String(name string) (string, error) {
+	raw, _, err := r.field(name)
+	return string(raw.data), err
+}
+
+// Int parses the named field as a base 10 integer. An empty field is 0.
+func (r *RawRecord) Int(name string) (int, error) {
+	raw, sf, err := r.field(name)
+	if err != nil || len(raw.data) == 0 {
+		return 0, err
+	}
+	i, err := parse.Int(raw.data)
+	if err != nil {
+		return 0, r.typeError(raw, reflect.TypeOf(i), sf, err)
+	}
+	return i, nil
+}
+
+// Time parses the named field as a time in the RFC 3339 format. Unlike Decode, which
+// fails on an empty time.Time field, Time returns the zero time for an empty field, as
+// the other accessors return the zero value of their type.
+func (r *RawRecord) Time(name string) (time.Time, error) {
+	var tm time.Time
+	raw, sf, err := r.field(name)
+	if err != nil || len(raw.data) == 0 {
+		return tm, err
+	}
+	if err := tm.UnmarshalText(raw.data); err != nil {
+		return time.Time{}, r.typeError(raw, reflect.TypeOf(tm), sf, err)
+	}
+	return tm, nil
+}
+
+// DecodeInto decodes the whole record into the value pointed to by v, in the same way as
+// Decode.
+func (r *RawRecord) DecodeInto(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	return r.d.decodeLine(rv, r.d.valueSetter(rv.Type()), r.line.data, r.meta)
+}
+
+// field returns the trimmed value of the named field and the field.
+func (r *RawRecord) field(name string) (rawBytes, reflect.StructField, error) {
+	sf, ok := r.t.FieldByName(name)
+	if !ok || len(sf.Index) != 1 || !r.ss.fieldSpecs[sf.Index[0]].ok {
+		return rawBytes{}, sf, errors.New("fixedwidth: unknown field " + r.t.Name() + "." + name)
+	}
+	spec := r.ss.fieldSpecs[sf.Index[0]]
+	return rawValueFromLine(r.line, spec.startPos, spec.endPos, spec.format), sf, nil
+}
+
+func (r *RawRecord) typeError(raw rawBytes, t reflect.Type, sf reflect.StructField, cause error) error {
+	return &UnmarshalTypeError{
+		Value:  string(raw.data),
+		Type:   t,
+		Struct: r.t.Name(),
+		Field:  sf.Name,
+		Cause:  cause,
+		Line:   r.meta.lineNum,
+	}
+}
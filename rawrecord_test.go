@@ -0,0 +1,102 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDecoder_DecodeRaw(t *testing.T) {
+	type person struct {
+		ID     int       `fixed:"1,5,right,0"`
+		Name   string    `fixed:"6,13,left,*"`
+		City   string    `fixed:"14,21"`
+		Joined time.Time `fixed:"22,41"`
+		Skip   string
+	}
+
+	input := "00042Ian***** Paris  2020-01-02T03:04:05Z\n" +
+		"0004xJane****        \n"
+	d := NewDecoder(bytes.NewReader([]byte(input)))
+
+	r, err := d.DecodeRaw((*person)(nil))
+	if err != nil {
+		t.Fatalf("DecodeRaw() unexpected error: %v", err)
+	}
+	second, err := d.DecodeRaw(person{})
+	if err != nil {
+		t.Fatalf("DecodeRaw() unexpected error: %v", err)
+	}
+	if _, err := d.DecodeRaw(person{}); err != io.EOF {
+		t.Fatalf("DecodeRaw() want io.EOF, have %v", err)
+	}
+
+	if r.Line() != 1 || string(r.Bytes()) != input[:41] {
+		t.Errorf("Line(), Bytes() want 1, %q, have %d, %q", input[:41], r.Line(), r.Bytes())
+	}
+	if v, err := r.Field("Name"); err != nil || string(v) != "Ian" {
+		t.Errorf("Field(Name) want Ian, have %q, %v", v, err)
+	}
+	if v, err := r.String("City"); err != nil || v != "Paris" {
+		t.Errorf("String(City) want Paris, have %q, %v", v, err)
+	}
+	if v, err := r.Int("ID"); err != nil || v != 42 {
+		t.Errorf("Int(ID) want 42, have %d, %v", v, err)
+	}
+	want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	if v, err := r.Time("Joined"); err != nil || !v.Equal(want) {
+		t.Errorf("Time(Joined) want %v, have %v, %v", want, v, err)
+	}
+	if _, err := r.String("Skip"); err == nil {
+		t.Errorf("String(Skip) want error for untagged field")
+	}
+	if _, err := r.String("Missing"); err == nil {
+		t.Errorf("String(Missing) want error for unknown field")
+	}
+
+	var p person
+	if err := r.DecodeInto(&p); err != nil {
+		t.Fatalf("DecodeInto() unexpected error: %v", err)
+	}
+	if wantP := (person{ID: 42, Name: "Ian", City: "Paris", Joined: want}); !reflect.DeepEqual(p, wantP) {
+		t.Errorf("DecodeInto() want %+v, have %+v", wantP, p)
+	}
+
+	// Empty fields have zero values, invalid ones report their line.
+	if v, err := second.Time("Joined"); err != nil || !v.IsZero() {
+		t.Errorf("Time(Joined) want zero time, have %v, %v", v, err)
+	}
+	var typeErr *UnmarshalTypeError
+	if _, err := second.Int("ID"); !errors.As(err, &typeErr) || typeErr.Line != 2 || typeErr.Field != "ID" || typeErr.Value != "4x" {
+		t.Errorf("Int(ID) want error for field ID on line 2, have %v", err)
+	}
+	if err := second.DecodeInto(&p); !errors.As(err, &typeErr) || typeErr.Line != 2 {
+		t.Errorf("DecodeInto() want error on line 2, have %v", err)
+	}
+
+	if _, err := NewDecoder(bytes.NewReader([]byte(input))).DecodeRaw(1); err == nil {
+		t.Errorf("DecodeRaw(1) want error for non-struct schema")
+	}
+}
+
+func TestDecoder_DecodeRaw_codepoints(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,3"`
+		B string `fixed:"4,6,right,*"`
+	}
+	d := NewDecoder(bytes.NewReader([]byte("ĀĂĄ**Ć\n")))
+	d.SetUseCodepointIndices(true)
+	r, err := d.DecodeRaw(S{})
+	if err != nil {
+		t.Fatalf("DecodeRaw() unexpected error: %v", err)
+	}
+	if v, _ := r.String("A"); v != "ĀĂĄ" {
+		t.Errorf("String(A) want %q, have %q", "ĀĂĄ", v)
+	}
+	if v, _ := r.String("B"); v != "Ć" {
+		t.Errorf("String(B) want %q, have %q", "Ć", v)
+	}
+}
@@ -0,0 +1,130 @@
+package fixedwidth
+
+import (
+	"errors"
+	"io"
+	"reflect"
+)
+
+// ErrRecordIndex is returned by RecordReader.ReadRecord for an index outside of
+// [0, Len()).
+var ErrRecordIndex = errors.New("fixedwidth: record index out of range")
+
+// A RecordReader decodes records by index from fixed-width data in which every record
+// has the same length in bytes. Record i starts at byte i*(recordLength+terminatorLength)
+// of the input, so any record can be read without reading the records before it.
+//
+// A RecordReader is not safe for concurrent use.
+type RecordReader struct {
+	r    io.ReaderAt
+	data []byte
+	size int64
+
+	recordLength     int
+	terminatorLength int
+
+	d   Decoder
+	buf []byte
+
+	lastType   reflect.Type
+	lastSetter lineSetter
+}
+
+// NewRecordReader returns a RecordReader that reads records of recordLength bytes from
+// the first size bytes of r. Records are separated by terminatorLength bytes, such as 1
+// for "\n" or 0 for blocked files. The terminator after the last record is optional.
+//
+// To read from an *os.File, pass the size reported by its Stat method.
+func NewRecordReader(r io.ReaderAt, size int64, recordLength, terminatorLength int) *RecordReader {
+	return &RecordReader{
+		r:                r,
+		size:             size,
+		recordLength:     recordLength,
+		terminatorLength: terminatorLength,
+	}
+}
+
+// NewRecordReaderBytes is like NewRecordReader but reads from data, which may be a
+// memory-mapped file. Records are decoded from data directly instead of being copied.
+func NewRecordReaderBytes(data []byte, recordLength, terminatorLength int) *RecordReader {
+	return &RecordReader{
+		data:             data,
+		size:             int64(len(data)),
+		recordLength:     recordLength,
+		terminatorLength: terminatorLength,
+	}
+}
+
+// SetUseCodepointIndices configures whether the indices in the `fixed` struct tags are
+// expressed in terms of bytes (the default behavior) or in terms of UTF-8 decoded
+// codepoints. The record length is always in bytes.
+func (r *RecordReader) SetUseCodepointIndices(use bool) {
+	r.d.useCodepointIndices = use
+	r.lastType = nil
+}
+
+// Len returns the number of records in the input. A short final record is not counted.
+func (r *RecordReader) Len() int {
+	return recordCount(r.size, r.recordLength, r.terminatorLength)
+}
+
+// recordCount returns the number of whole records in size bytes of data. The
+// terminator after the last record is optional.
+func recordCount(size int64, recordLength, terminatorLength int) int {
+	stride := int64(recordLength + terminatorLength)
+	if stride <= 0 {
+		return 0
+	}
+	return int((size + int64(terminatorLength)) / stride)
+}
+
+// ReadRecord decodes the record with index i, starting at 0, into the value pointed to
+// by v. It returns ErrRecordIndex if i is not less than Len.
+//
+// Errors decoding a record report the record as the line it occurred on, counting from 1.
+func (r *RecordReader) ReadRecord(i int, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &InvalidUnmarshalError{reflect.TypeOf(v)}
+	}
+	t := rv.Type()
+	if t != r.lastType {
+		r.lastType = t
+		r.lastSetter = r.d.valueSetter(t)
+	}
+	return r.readRecordWith(i, rv, r.lastSetter)
+}
+
+// readRecordWith is like ReadRecord but decodes the record using the provided
+// valueSetter.
+func (r *RecordReader) readRecordWith(i int, v reflect.Value, setter lineSetter) error {
+	record, err := r.record(i)
+	if err != nil {
+		return err
+	}
+	return r.d.decodeLine(v, setter, record, lineMeta{lineNum: i + 1, offset: int64(i) * int64(r.recordLength+r.terminatorLength)})
+}
+
+// record returns the data of the record with index i. The returned slice is only valid
+// until the next call to record.
+func (r *RecordReader) record(i int) ([]byte, error) {
+	if i < 0 || i >= r.Len() {
+		return nil, ErrRecordIndex
+	}
+	off := int64(i) * int64(r.recordLength+r.terminatorLength)
+	if r.r == nil {
+		return r.data[off : off+int64(r.recordLength)], nil
+	}
+
+	if r.buf == nil {
+		r.buf = make([]byte, r.recordLength)
+	}
+	n, err := r.r.ReadAt(r.buf, off)
+	if n < len(r.buf) {
+		if err == io.EOF || err == nil {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return r.buf, nil
+}
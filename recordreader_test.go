@@ -0,0 +1,110 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRecordReader(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,3"`
+		B int    `fixed:"4,6"`
+	}
+
+	data := []byte("foo  1\nbar  2\nbaz  3\n")
+	path := filepath.Join(t.TempDir(), "records")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	for _, tt := range []struct {
+		name string
+		r    *RecordReader
+		len  int
+	}{
+		{"ReaderAt", NewRecordReader(bytes.NewReader(data), int64(len(data)), 6, 1), 3},
+		{"ReaderAt without final terminator", NewRecordReader(bytes.NewReader(data), int64(len(data)-1), 6, 1), 3},
+		{"File", NewRecordReader(f, int64(len(data)), 6, 1), 3},
+		{"Bytes", NewRecordReaderBytes(data, 6, 1), 3},
+		{"Bytes with short record", NewRecordReaderBytes(data[:len(data)-3], 6, 1), 2},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if l := tt.r.Len(); l != tt.len {
+				t.Fatalf("Len() want %d, have %d", tt.len, l)
+			}
+
+			var v S
+			if err := tt.r.ReadRecord(1, &v); err != nil {
+				t.Fatalf("ReadRecord() unexpected error: %v", err)
+			}
+			if want := (S{"bar", 2}); v != want {
+				t.Errorf("ReadRecord() want %+v, have %+v", want, v)
+			}
+			if err := tt.r.ReadRecord(0, &v); err != nil {
+				t.Fatalf("ReadRecord() unexpected error: %v", err)
+			}
+			if want := (S{"foo", 1}); v != want {
+				t.Errorf("ReadRecord() want %+v, have %+v", want, v)
+			}
+
+			for _, i := range []int{-1, tt.len} {
+				if err := tt.r.ReadRecord(i, &v); err != ErrRecordIndex {
+					t.Errorf("ReadRecord(%d) want ErrRecordIndex, have %v", i, err)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordReader_errors(t *testing.T) {
+	type S struct {
+		A int `fixed:"1,3"`
+	}
+
+	r := NewRecordReaderBytes([]byte("  1abc"), 3, 0)
+	var v S
+	err := r.ReadRecord(1, &v)
+	if e, ok := err.(*UnmarshalTypeError); !ok || e.Line != 2 {
+		t.Errorf("ReadRecord() want *UnmarshalTypeError on line 2, have %v", err)
+	}
+	if err := r.ReadRecord(0, v); err == nil {
+		t.Errorf("ReadRecord() want error for non-pointer")
+	}
+
+	// The ReaderAt is shorter than the size it was constructed with.
+	r = NewRecordReader(bytes.NewReader([]byte("  1ab")), 6, 3, 0)
+	if err := r.ReadRecord(1, &v); err != io.ErrUnexpectedEOF {
+		t.Errorf("ReadRecord() want io.ErrUnexpectedEOF, have %v", err)
+	}
+}
+
+func TestRecordReader_useCodepointIndices(t *testing.T) {
+	type S struct {
+		A string `fixed:"1,2"`
+		B string `fixed:"3,3"`
+	}
+
+	// Records are 5 bytes long regardless of the number of codepoints.
+	r := NewRecordReaderBytes([]byte("☃abbcd"), 5, 0)
+	r.SetUseCodepointIndices(true)
+	var have []S
+	for i := 0; i < r.Len(); i++ {
+		var v S
+		if err := r.ReadRecord(i, &v); err != nil {
+			t.Fatalf("ReadRecord() unexpected error: %v", err)
+		}
+		have = append(have, v)
+	}
+	if want := []S{{"☃a", "b"}}; !reflect.DeepEqual(have, want) {
+		t.Errorf("ReadRecord() want %+v, have %+v", want, have)
+	}
+}
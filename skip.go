@@ -0,0 +1,152 @@
+package fixedwidth
+
+import "bytes"
+
+// Reasons for which a line is skipped by the Decoder.
+const (
+	SkippedHeader   = "header"
+	SkippedTrailer  = "trailer"
+	SkippedComment  = "comment"
+	SkippedBlank    = "blank"
+	SkippedFiltered = "filtered"
+)
+
+// A SkippedLine is a line of the input that was skipped by the Decoder.
+type SkippedLine struct {
+	Line   int    // line of the input, starting at 1
+	Data   []byte // the line, excluding its terminator
+	Reason string // one of SkippedHeader, SkippedTrailer, and so on
+}
+
+// SetSkipHeader configures the Decoder to skip the first n lines of its input, such as
+// banner or column header lines.
+func (d *Decoder) SetSkipHeader(n int) {
+	d.skipHeader = n
+}
+
+// SetSkipTrailer configures the Decoder to skip the last n lines of its input, such as a
+// summary line. The Decoder reads n lines ahead to recognize them, so the lines are
+// copied out of its buffer.
+func (d *Decoder) SetSkipTrailer(n int) {
+	d.skipTrailer = n
+}
+
+// SetCommentPrefix configures the Decoder to skip lines that start with any of the
+// given prefixes, such as "#". Calling SetCommentPrefix without prefixes stops skipping
+// comments.
+func (d *Decoder) SetCommentPrefix(prefixes ...string) {
+	d.commentPrefixes = d.commentPrefixes[:0]
+	for _, p := range prefixes {
+		if p != "" {
+			d.commentPrefixes = append(d.commentPrefixes, []byte(p))
+		}
+	}
+}
+
+// SetSkipBlankLines configures the Decoder to skip lines that are empty or only hold
+// whitespace.
+func (d *Decoder) SetSkipBlankLines(skip bool) {
+	d.skipBlankLines = skip
+}
+
+// SetSkipHandler sets a function that is called with every line skipped because of
+// SetSkipHeader, SetSkipTrailer, SetCommentPrefix, SetSkipBlankLines, or a line filter,
+// for example to log or audit them. SkippedLine.Data is a copy of the line that may be
+// retained.
+func (d *Decoder) SetSkipHandler(fn func(SkippedLine)) {
+	d.skipHandler = fn
+}
+
+// skipReason returns the reason the current line should be skipped, or "" if it should
+// be decoded. Header lines are recognized first, then blank lines, comments, and lines
+// rejected by the line filter.
+func (d *Decoder) skipReason(line []byte) string {
+	switch {
+	case d.lineNum <= d.skipHeader:
+		return SkippedHeader
+	case d.skipBlankLines && len(bytes.TrimSpace(line)) == 0:
+		return SkippedBlank
+	case d.isComment(line):
+		return SkippedComment
+	case d.lineFilter != nil && !d.lineFilter(line):
+		return SkippedFiltered
+	}
+	return ""
+}
+
+func (d *Decoder) isComment(line []byte) bool {
+	for _, p := range d.commentPrefixes {
+		if bytes.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// skip reports the current line to the skip handler.
+func (d *Decoder) skip(line []byte, reason string) {
+	if d.skipHandler != nil {
+		d.skipHandler(SkippedLine{Line: d.lineNum, Data: append(make([]byte, 0, len(line)), line...), Reason: reason})
+	}
+}
+
+// A scannedLine is a line that was read ahead of the line being decoded.
+type scannedLine struct {
+	data       []byte
+	terminator []byte
+	end        int64 // offset of the end of the line in the input
+}
+
+// scanLine reads the next line of the input, or returns false at its end. Lines that
+// are part of the trailer are skipped.
+func (d *Decoder) scanLine() (line []byte, ok bool, err error) {
+	if !d.scanStarted {
+		d.startScan()
+	}
+	if d.skipTrailer <= 0 {
+		if !d.scanner.Scan() {
+			return nil, false, d.scanError(d.scanner.Err())
+		}
+		d.lineNum++
+		d.offset = d.scanOffset
+		d.terminator = d.scanTerminator
+		return d.scanner.Bytes(), true, nil
+	}
+
+	// Keep skipTrailer lines buffered after the one that is returned, so that the
+	// trailer is known when the input ends.
+	for !d.scanDone && len(d.ahead) <= d.skipTrailer {
+		if !d.scanner.Scan() {
+			if err := d.scanner.Err(); err != nil {
+				return nil, false, d.scanError(err)
+			}
+			d.scanDone = true
+			break
+		}
+		// Reuse the buffer of the line returned by the previous call, which is no
+		// longer valid.
+		d.ahead = append(d.ahead, scannedLine{
+			data:       append(d.spare[:0], d.scanner.Bytes()...),
+			terminator: d.scanTerminator,
+			end:        d.scanOffset,
+		})
+		d.spare = nil
+	}
+	if len(d.ahead) <= d.skipTrailer {
+		for _, l := range d.ahead {
+			d.lineNum++
+			d.offset = l.end
+			d.terminator = l.terminator
+			d.skip(l.data, SkippedTrailer)
+		}
+		d.ahead = nil
+		return nil, false, nil
+	}
+	l := d.ahead[0]
+	d.ahead = d.ahead[1:]
+	d.spare = l.data
+	d.lineNum++
+	d.offset = l.end
+	d.terminator = l.terminator
+	return l.data, true, nil
+}
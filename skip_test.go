@@ -0,0 +1,135 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder_Skip(t *testing.T) {
+	type S struct {
+		ID   int    `fixed:"1,3"`
+		Name string `fixed:"4,8"`
+	}
+
+	const input = "" +
+		"PARTNER FEED\n" +
+		" ID NAME\n" +
+		"  1Ann\n" +
+		"# a comment\n" +
+		"\n" +
+		"  2Bob\n" +
+		"   \t\n" +
+		"  3Cy\n" +
+		"TOTAL 3\n"
+
+	setup := func(d *Decoder) {
+		d.SetSkipHeader(2)
+		d.SetSkipTrailer(1)
+		d.SetCommentPrefix("#", "//")
+		d.SetSkipBlankLines(true)
+	}
+	want := []S{{1, "Ann"}, {2, "Bob"}, {3, "Cy"}}
+	wantSkipped := []SkippedLine{
+		{1, []byte("PARTNER FEED"), SkippedHeader},
+		{2, []byte(" ID NAME"), SkippedHeader},
+		{4, []byte("# a comment"), SkippedComment},
+		{5, []byte(""), SkippedBlank},
+		{7, []byte("   \t"), SkippedBlank},
+		{9, []byte("TOTAL 3"), SkippedTrailer},
+	}
+
+	for _, concurrency := range []int{0, 2} {
+		d := NewDecoder(strings.NewReader(input))
+		d.SetConcurrency(concurrency)
+		setup(d)
+		var skipped []SkippedLine
+		d.SetSkipHandler(func(l SkippedLine) { skipped = append(skipped, l) })
+
+		var have []S
+		if err := d.Decode(&have); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("Decode() want %+v, have %+v", want, have)
+		}
+		if !reflect.DeepEqual(skipped, wantSkipped) {
+			t.Errorf("Decode() skipped want %q, have %q", wantSkipped, skipped)
+		}
+	}
+
+	t.Run("one value at a time", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader(input))
+		setup(d)
+		var have []S
+		for {
+			var s S
+			err := d.Decode(&s)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			have = append(have, s)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("Decode() want %+v, have %+v", want, have)
+		}
+	})
+
+	t.Run("errors report line numbers", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("header\n  1Ann\n  xBob\ntrailer\n"))
+		d.SetSkipHeader(1)
+		d.SetSkipTrailer(1)
+		var have []S
+		var typeErr *UnmarshalTypeError
+		if err := d.Decode(&have); !errors.As(err, &typeErr) || typeErr.Line != 3 {
+			t.Fatalf("Decode() want error on line 3, have %v", err)
+		}
+	})
+
+	t.Run("input shorter than trailer", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("  1Ann\n  2Bob\n"))
+		d.SetSkipTrailer(3)
+		var skipped []SkippedLine
+		d.SetSkipHandler(func(l SkippedLine) { skipped = append(skipped, l) })
+		var have []S
+		if err := d.Decode(&have); err != nil || have != nil {
+			t.Fatalf("Decode() want no values, have %+v, %v", have, err)
+		}
+		if len(skipped) != 2 || skipped[1].Line != 2 || skipped[1].Reason != SkippedTrailer {
+			t.Errorf("Decode() skipped want 2 trailer lines, have %q", skipped)
+		}
+	})
+
+	t.Run("filtered lines are reported", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("  1Ann\n  2Bob\n"))
+		d.SetLineFilter(func(line []byte) bool { return !bytes.Contains(line, []byte("Bob")) })
+		var skipped []SkippedLine
+		d.SetSkipHandler(func(l SkippedLine) { skipped = append(skipped, l) })
+		var have []S
+		if err := d.Decode(&have); err != nil {
+			t.Fatalf("Decode() unexpected error: %v", err)
+		}
+		if want := []SkippedLine{{2, []byte("  2Bob"), SkippedFiltered}}; !reflect.DeepEqual(skipped, want) {
+			t.Errorf("Decode() skipped want %q, have %q", want, skipped)
+		}
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("ID\nb\na\nEND\n"))
+		d.SetSkipHeader(1)
+		d.SetSkipTrailer(1)
+		var buf bytes.Buffer
+		if err := Sort(&buf, d, []SortKey{{Start: 1, End: 1}}, nil); err != nil {
+			t.Fatalf("Sort() unexpected error: %v", err)
+		}
+		if buf.String() != "a\nb\n" {
+			t.Errorf("Sort() want %q, have %q", "a\nb\n", buf.String())
+		}
+	})
+}
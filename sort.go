@@ -0,0 +1,512 @@
+package fixedwidth
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"sort"
+)
+
+// defaultSortMemoryLimit is the memory limit used by Sort when none is set.
+const defaultSortMemoryLimit = 64 << 20
+
+// defaultSortMaxOpenFiles is the number of temporary files open at a time when none is
+// set.
+const defaultSortMaxOpenFiles = 64
+
+// A SortKey describes a field that records are sorted by.
+//
+// Start and End follow the same rules as the positions of the `fixed` struct tag. The
+// value of the field is trimmed of spaces before it is compared. Values are compared
+// byte by byte unless Numeric is set, in which case they are compared as decimal
+// numbers of any length. Values that are not numbers sort before those that are.
+type SortKey struct {
+	Start, End int
+	Descending bool
+	Numeric    bool
+}
+
+// SortKey returns a SortKey for the field of the layout with the given name. The key is
+// numeric if the field has the int or decimal type.
+func (l *Layout) SortKey(name string) (SortKey, error) {
+	i := l.Index(name)
+	if i < 0 {
+		return SortKey{}, errors.New("fixedwidth: unknown layout field " + name)
+	}
+	f := l.Fields[i]
+	return SortKey{
+		Start:   f.Start,
+		End:     f.End,
+		Numeric: f.Type == TypeInt || f.Type == TypeDecimal,
+	}, nil
+}
+
+// SortOptions configures Sort.
+type SortOptions struct {
+	// MemoryLimit is the approximate number of bytes of records held in memory at a
+	// time. Larger inputs are sorted in runs that are written to temporary files and
+	// merged. The default is 64 MiB.
+	MemoryLimit int
+
+	// TempDir is the directory temporary files are created in. The default is the
+	// directory returned by os.TempDir.
+	TempDir string
+
+	// MaxOpenFiles is the maximum number of temporary files open at a time. Each run is
+	// closed once it has been written and reopened to be merged. If there are more runs
+	// than MaxOpenFiles, groups of them are merged into new runs first, in as many
+	// passes as needed. The default is 64 and the minimum is 3.
+	MaxOpenFiles int
+}
+
+// Sort reads the remaining records from d, sorts them by keys, and writes them to w.
+// Records are compared by the first key, then by the second, and so on. Records with
+// equal keys keep their order from the input.
+//
+// Records are read using the configuration of d, such as its line terminators or record
+// length, and are written unchanged, each followed by the terminator it had in the
+// input. The last line of an input that does not end with a terminator is given the
+// first line terminator of d unless it is also written last, so that the output ends
+// with a terminator exactly when the input does. A byte order mark stripped by d is
+// written at the start of the output.
+func Sort(w io.Writer, d *Decoder, keys []SortKey, opts *SortOptions) error {
+	if len(keys) == 0 {
+		return errors.New("fixedwidth: Sort requires at least one key")
+	}
+	for _, k := range keys {
+		if k.Start < 1 || k.End < k.Start {
+			return errors.New("fixedwidth: invalid sort key interval")
+		}
+	}
+	s := &sorter{d: d, keys: keys, limit: defaultSortMemoryLimit, maxOpenFiles: defaultSortMaxOpenFiles}
+	if opts != nil {
+		if opts.MemoryLimit > 0 {
+			s.limit = opts.MemoryLimit
+		}
+		s.tempDir = opts.TempDir
+		if opts.MaxOpenFiles > 0 {
+			s.maxOpenFiles = opts.MaxOpenFiles
+			if s.maxOpenFiles < 3 {
+				s.maxOpenFiles = 3
+			}
+		}
+	}
+	defer s.cleanup()
+
+	bw := bufio.NewWriter(w)
+	if err := s.sort(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// sortRecordOverhead approximates the memory used by a sortRecord in addition to its
+// data.
+const sortRecordOverhead = 64
+
+// A sortRecord is a record and the values of its keys, which alias data. data holds
+// the record followed by its terminator, which starts at n.
+type sortRecord struct {
+	data []byte
+	n    int
+	keys [][]byte
+}
+
+type sorter struct {
+	d            *Decoder
+	keys         []SortKey
+	limit        int
+	tempDir      string
+	maxOpenFiles int
+
+	records []sortRecord
+	size    int
+
+	// runs holds the names of the temporary files of the runs, which are closed while
+	// they are not being written or merged.
+	runs []string
+
+	// unterminated is set if the last line of the input has no terminator. wrote is
+	// set once the first record has been written, and terminator is the terminator
+	// that follows it once the next record is written.
+	unterminated bool
+	wrote        bool
+	terminator   []byte
+}
+
+func (s *sorter) sort(w *bufio.Writer) error {
+	for {
+		line, ok, err := s.d.nextLine()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		term := s.d.terminator
+		if term == nil && s.d.recordLength <= 0 {
+			s.unterminated = true
+		}
+		data := append(append(make([]byte, 0, len(line)+len(term)), line...), term...)
+		s.records = append(s.records, sortRecord{data: data, n: len(line), keys: s.keyValues(data[:len(line)])})
+		s.size += len(data) + sortRecordOverhead
+		if s.size >= s.limit {
+			if err := s.spill(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if s.d.strippedBOM {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return err
+		}
+	}
+	if len(s.runs) == 0 {
+		s.sortRecords()
+		for _, r := range s.records {
+			if err := s.writeRecord(w, r); err != nil {
+				return err
+			}
+		}
+		return s.finish(w)
+	}
+	if len(s.records) > 0 {
+		if err := s.spill(); err != nil {
+			return err
+		}
+	}
+	for len(s.runs) > s.maxOpenFiles {
+		if err := s.mergePass(); err != nil {
+			return err
+		}
+	}
+	if err := s.merge(s.runs, func(r sortRecord) error { return s.writeRecord(w, r) }); err != nil {
+		return err
+	}
+	return s.finish(w)
+}
+
+// keyValues returns the values of the keys of the record data.
+func (s *sorter) keyValues(data []byte) [][]byte {
+	line := newRawBytes(data, s.d.useCodepointIndices)
+	values := make([][]byte, len(s.keys))
+	for i, k := range s.keys {
+		values[i] = bytes.Trim(columnOf(line, k.Start, k.End), " ")
+	}
+	return values
+}
+
+func (s *sorter) compare(a, b [][]byte) int {
+	for i, k := range s.keys {
+		var c int
+		if k.Numeric {
+			c = compareNumeric(a[i], b[i])
+		} else {
+			c = bytes.Compare(a[i], b[i])
+		}
+		if k.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func (s *sorter) sortRecords() {
+	sort.SliceStable(s.records, func(i, j int) bool {
+		return s.compare(s.records[i].keys, s.records[j].keys) < 0
+	})
+}
+
+// writeRecord writes r to the output. The terminator of r is only written along with the
+// next record, or by finish, as the last record is not terminated if the last line of
+// the input was not.
+func (s *sorter) writeRecord(w *bufio.Writer, r sortRecord) error {
+	if s.wrote {
+		if _, err := w.Write(s.terminator); err != nil {
+			return err
+		}
+	}
+	if _, err := w.Write(r.data[:r.n]); err != nil {
+		return err
+	}
+	s.wrote = true
+	s.terminator = r.data[r.n:]
+	if len(s.terminator) == 0 && s.d.recordLength <= 0 {
+		s.terminator = s.d.lineTerminator
+	}
+	return nil
+}
+
+// finish writes the terminator of the last record.
+func (s *sorter) finish(w *bufio.Writer) error {
+	if !s.wrote || s.unterminated {
+		return nil
+	}
+	_, err := w.Write(s.terminator)
+	return err
+}
+
+// spill sorts the records held in memory and writes them to a temporary file as a run.
+func (s *sorter) spill() error {
+	s.sortRecords()
+
+	name, err := s.createRun(func(rw *runWriter) error {
+		for _, r := range s.records {
+			if err := rw.write(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, name)
+
+	for i := range s.records {
+		s.records[i] = sortRecord{}
+	}
+	s.records = s.records[:0]
+	s.size = 0
+	return nil
+}
+
+// createRun writes a run to a new temporary file using fill and returns the name of the
+// file, which is closed.
+func (s *sorter) createRun(fill func(rw *runWriter) error) (string, error) {
+	f, err := os.CreateTemp(s.tempDir, "fixedwidth-sort-")
+	if err != nil {
+		return "", err
+	}
+	rw := &runWriter{w: bufio.NewWriter(f)}
+	err = fill(rw)
+	if err == nil {
+		err = rw.w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// mergePass merges groups of runs into new runs, which replace them. A group has one
+// run less than maxOpenFiles to leave room for the new run. Groups are made of
+// consecutive runs and stay in order, which keeps the sort stable.
+func (s *sorter) mergePass() error {
+	var merged []string
+	defer func() {
+		// Leave every run that has not been removed for cleanup.
+		s.runs = append(merged, s.runs...)
+	}()
+	for len(s.runs) > 0 {
+		n := s.maxOpenFiles - 1
+		if n > len(s.runs) {
+			n = len(s.runs)
+		}
+		name, err := s.createRun(func(rw *runWriter) error {
+			return s.merge(s.runs[:n], rw.write)
+		})
+		if err != nil {
+			return err
+		}
+		merged = append(merged, name)
+		removeRuns(s.runs[:n])
+		s.runs = s.runs[n:]
+	}
+	return nil
+}
+
+// merge merges the runs with the given names and calls emit with each record in order.
+func (s *sorter) merge(runs []string, emit func(sortRecord) error) error {
+	h := &runHeap{s: s}
+	for i, name := range runs {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r := &run{index: i, r: bufio.NewReader(f)}
+		ok, err := r.next(s)
+		if err != nil {
+			return err
+		}
+		if ok {
+			h.runs = append(h.runs, r)
+		}
+	}
+	heap.Init(h)
+
+	for len(h.runs) > 0 {
+		r := h.runs[0]
+		if err := emit(r.record); err != nil {
+			return err
+		}
+		ok, err := r.next(s)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+	}
+	return nil
+}
+
+// cleanup removes the temporary files.
+func (s *sorter) cleanup() {
+	removeRuns(s.runs)
+}
+
+func removeRuns(runs []string) {
+	for _, name := range runs {
+		os.Remove(name)
+	}
+}
+
+// A runWriter writes the records of a run to a temporary file. Each record is written
+// with the length of its data and of the record without its terminator as a prefix.
+type runWriter struct {
+	w *bufio.Writer
+	n [binary.MaxVarintLen64]byte
+}
+
+func (rw *runWriter) write(r sortRecord) error {
+	for _, n := range []int{len(r.data), r.n} {
+		if _, err := rw.w.Write(rw.n[:binary.PutUvarint(rw.n[:], uint64(n))]); err != nil {
+			return err
+		}
+	}
+	_, err := rw.w.Write(r.data)
+	return err
+}
+
+// A run is a sorted run of records being merged.
+type run struct {
+	index  int
+	r      *bufio.Reader
+	record sortRecord
+}
+
+// next reads the next record of the run. False is returned at the end of the run.
+func (r *run) next(s *sorter) (bool, error) {
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	n, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return false, err
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r.r, data); err != nil {
+		return false, err
+	}
+	r.record = sortRecord{data: data, n: int(n), keys: s.keyValues(data[:n])}
+	return true, nil
+}
+
+// runHeap orders runs by their current record. Runs with equal records are ordered by
+// their index, which keeps the sort stable.
+type runHeap struct {
+	s    *sorter
+	runs []*run
+}
+
+func (h *runHeap) Len() int { return len(h.runs) }
+
+func (h *runHeap) Less(i, j int) bool {
+	if c := h.s.compare(h.runs[i].record.keys, h.runs[j].record.keys); c != 0 {
+		return c < 0
+	}
+	return h.runs[i].index < h.runs[j].index
+}
+
+func (h *runHeap) Swap(i, j int) { h.runs[i], h.runs[j] = h.runs[j], h.runs[i] }
+
+func (h *runHeap) Push(x interface{}) { h.runs = append(h.runs, x.(*run)) }
+
+func (h *runHeap) Pop() interface{} {
+	r := h.runs[len(h.runs)-1]
+	h.runs = h.runs[:len(h.runs)-1]
+	return r
+}
+
+// compareNumeric compares a and b as decimal numbers, such as -12 or 003.50, of any
+// length. Values that are not numbers sort before numbers and are compared byte by
+// byte.
+func compareNumeric(a, b []byte) int {
+	aNeg, aInt, aFrac, aOK := parseDecimal(a)
+	bNeg, bInt, bFrac, bOK := parseDecimal(b)
+	switch {
+	case !aOK && !bOK:
+		return bytes.Compare(a, b)
+	case !aOK:
+		return -1
+	case !bOK:
+		return 1
+	case aNeg != bNeg:
+		if aNeg {
+			return -1
+		}
+		return 1
+	}
+
+	c := len(aInt) - len(bInt)
+	if c == 0 {
+		c = bytes.Compare(aInt, bInt)
+	}
+	if c == 0 {
+		c = bytes.Compare(aFrac, bFrac)
+	}
+	if aNeg {
+		c = -c
+	}
+	return c
+}
+
+// parseDecimal splits the decimal number b into its sign and its integer and fraction
+// digits, without leading zeros in the integer part or trailing zeros in the fraction.
+// Zero is never negative. ok is false if b is not a number.
+func parseDecimal(b []byte) (neg bool, intPart, frac []byte, ok bool) {
+	if len(b) > 0 && (b[0] == '-' || b[0] == '+') {
+		neg = b[0] == '-'
+		b = b[1:]
+	}
+	intPart = b
+	if i := bytes.IndexByte(b, '.'); i >= 0 {
+		intPart, frac = b[:i], b[i+1:]
+	}
+	if len(intPart) == 0 && len(frac) == 0 {
+		return false, nil, nil, false
+	}
+	for _, part := range [][]byte{intPart, frac} {
+		for _, c := range part {
+			if c < '0' || c > '9' {
+				return false, nil, nil, false
+			}
+		}
+	}
+	intPart = bytes.TrimLeft(intPart, "0")
+	frac = bytes.TrimRight(frac, "0")
+	if len(intPart) == 0 && len(frac) == 0 {
+		neg = false
+	}
+	return neg, intPart, frac, true
+}
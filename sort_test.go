@@ -0,0 +1,248 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestSort(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		in    string
+		keys  []SortKey
+		codec func(d *Decoder)
+		want  string
+	}{
+		{
+			name: "string",
+			in:   "b 2\na 3\nc 1",
+			keys: []SortKey{{Start: 1, End: 1}},
+			want: "a 3\nb 2\nc 1",
+		},
+		{
+			name: "unterminated line first",
+			in:   "b\na",
+			keys: []SortKey{{Start: 1, End: 1}},
+			want: "a\nb",
+		},
+		{
+			name: "descending",
+			in:   "b 2\na 3\nc 1\n",
+			keys: []SortKey{{Start: 1, End: 1, Descending: true}},
+			want: "c 1\nb 2\na 3\n",
+		},
+		{
+			name: "numeric",
+			in:   "  10x\n   9x\n -1 x\n  +2x\n  abx\n 0.5x\n",
+			keys: []SortKey{{Start: 1, End: 4, Numeric: true}},
+			want: "  abx\n -1 x\n 0.5x\n  +2x\n   9x\n  10x\n",
+		},
+		{
+			name: "multiple keys and stability",
+			in:   "a2 first\nb1 x\na1 y\na2 second\n",
+			keys: []SortKey{{Start: 1, End: 1}, {Start: 2, End: 2, Numeric: true, Descending: true}},
+			want: "a2 first\na2 second\na1 y\nb1 x\n",
+		},
+		{
+			name:  "crlf",
+			in:    "b\r\na\r\n",
+			keys:  []SortKey{{Start: 1, End: 1}},
+			codec: func(d *Decoder) { d.SetLineTerminator([]byte("\r\n")) },
+			want:  "a\r\nb\r\n",
+		},
+		{
+			name:  "mixed terminators",
+			in:    "c\rb\r\na\n",
+			keys:  []SortKey{{Start: 1, End: 1}},
+			codec: func(d *Decoder) { d.SetUniversalNewlines(true) },
+			want:  "a\nb\r\nc\r",
+		},
+		{
+			name:  "byte order mark",
+			in:    "\xef\xbb\xbfb\r\na\r\n",
+			keys:  []SortKey{{Start: 1, End: 1}},
+			codec: func(d *Decoder) { d.SetUniversalNewlines(true) },
+			want:  "\xef\xbb\xbfa\r\nb\r\n",
+		},
+		{
+			name:  "record length",
+			in:    "b1a2c3",
+			keys:  []SortKey{{Start: 1, End: 1}},
+			codec: func(d *Decoder) { d.SetRecordLength(2) },
+			want:  "a2b1c3",
+		},
+		{
+			name:  "codepoints",
+			in:    "☃b\n☃a\n",
+			keys:  []SortKey{{Start: 2, End: 2}},
+			codec: func(d *Decoder) { d.SetUseCodepointIndices(true) },
+			want:  "☃a\n☃b\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.in))
+			if tt.codec != nil {
+				tt.codec(d)
+			}
+			var buf bytes.Buffer
+			if err := Sort(&buf, d, tt.keys, nil); err != nil {
+				t.Fatalf("Sort() unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Sort() want %q, have %q", tt.want, buf.String())
+			}
+		})
+	}
+}
+
+func TestSort_runs(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	terminators := []string{"\n", "\r\n", "\r"}
+	var (
+		in   bytes.Buffer
+		want []string
+	)
+	for i := 0; i < 5000; i++ {
+		line := fmt.Sprintf("%06d%06d", rng.Intn(1000), i) + terminators[rng.Intn(len(terminators))]
+		in.WriteString(line)
+		want = append(want, line)
+	}
+	sort.SliceStable(want, func(i, j int) bool { return want[i][:6] < want[j][:6] })
+
+	for _, maxOpenFiles := range []int{0, 3} {
+		t.Run(fmt.Sprintf("max open files %d", maxOpenFiles), func(t *testing.T) {
+			dir := t.TempDir()
+			d := NewDecoder(bytes.NewReader(in.Bytes()))
+			d.SetUniversalNewlines(true)
+			var out bytes.Buffer
+			opts := &SortOptions{MemoryLimit: 10000, TempDir: dir, MaxOpenFiles: maxOpenFiles}
+			if err := Sort(&out, d, []SortKey{{Start: 1, End: 6, Numeric: true}}, opts); err != nil {
+				t.Fatalf("Sort() unexpected error: %v", err)
+			}
+			if out.String() != strings.Join(want, "") {
+				t.Errorf("Sort() returned records out of order or with changed terminators")
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("Sort() left %d temporary files", len(entries))
+			}
+		})
+	}
+}
+
+// fdCountingReader counts the files in dir that the process has open each time it is
+// read from.
+type fdCountingReader struct {
+	r   io.Reader
+	dir string
+	max int
+}
+
+func (r *fdCountingReader) Read(p []byte) (int, error) {
+	fds, _ := os.ReadDir("/proc/self/fd")
+	n := 0
+	for _, fd := range fds {
+		if target, err := os.Readlink(filepath.Join("/proc/self/fd", fd.Name())); err == nil && strings.HasPrefix(target, r.dir) {
+			n++
+		}
+	}
+	if n > r.max {
+		r.max = n
+	}
+	return r.r.Read(p)
+}
+
+func TestSort_openFiles(t *testing.T) {
+	if _, err := os.Stat("/proc/self/fd"); err != nil {
+		t.Skip("open files cannot be listed")
+	}
+	var in bytes.Buffer
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(&in, "%06d\n", (i*7919)%5000)
+	}
+	dir := t.TempDir()
+	r := &fdCountingReader{r: iotest.HalfReader(&in), dir: dir}
+	opts := &SortOptions{MemoryLimit: 4000, TempDir: dir, MaxOpenFiles: 3}
+	if err := Sort(io.Discard, NewDecoder(r), []SortKey{{Start: 1, End: 6}}, opts); err != nil {
+		t.Fatalf("Sort() unexpected error: %v", err)
+	}
+	if r.max > 1 {
+		t.Errorf("Sort() kept %d temporary files open while reading its input", r.max)
+	}
+}
+
+func TestSort_errors(t *testing.T) {
+	for _, keys := range [][]SortKey{nil, {{Start: 0, End: 1}}, {{Start: 3, End: 2}}} {
+		if err := Sort(new(bytes.Buffer), NewDecoder(strings.NewReader("a")), keys, nil); err == nil {
+			t.Errorf("Sort(%v) want error", keys)
+		}
+	}
+
+	d := NewDecoder(strings.NewReader("ab\nc\n"))
+	d.SetLineLengthRange(2, 2)
+	if err := Sort(new(bytes.Buffer), d, []SortKey{{Start: 1, End: 1}}, nil); err == nil {
+		t.Errorf("Sort() want error for invalid line")
+	}
+}
+
+func TestCompareNumeric(t *testing.T) {
+	// Each value is less than the next.
+	ordered := []string{"", "-", "abc", "-100", "-99.5", "-1", "0", "0.001", "1", "1.5", "2", "10", "99999999999999999999999"}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+			if c := compareNumeric([]byte(ordered[i]), []byte(ordered[j])); sign(c) != want {
+				t.Errorf("compareNumeric(%q, %q) want %d, have %d", ordered[i], ordered[j], want, c)
+			}
+		}
+	}
+
+	for _, eq := range [][2]string{{"1", "001"}, {"1.50", "1.5"}, {"-0", "0"}, {"+3", "3"}, {".5", "0.5"}} {
+		if c := compareNumeric([]byte(eq[0]), []byte(eq[1])); c != 0 {
+			t.Errorf("compareNumeric(%q, %q) want 0, have %d", eq[0], eq[1], c)
+		}
+	}
+}
+
+func sign(c int) int {
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
+}
+
+func TestLayout_SortKey(t *testing.T) {
+	l := &Layout{Fields: []LayoutField{
+		{Name: "Account", Start: 1, End: 10, Type: TypeInt},
+		{Name: "Name", Start: 11, End: 20},
+	}}
+	if k, err := l.SortKey("Account"); err != nil || k != (SortKey{Start: 1, End: 10, Numeric: true}) {
+		t.Errorf("SortKey(Account) = %+v, %v", k, err)
+	}
+	if k, err := l.SortKey("Name"); err != nil || k != (SortKey{Start: 11, End: 20}) {
+		t.Errorf("SortKey(Name) = %+v, %v", k, err)
+	}
+	if _, err := l.SortKey("Missing"); err == nil {
+		t.Errorf("SortKey(Missing) want error")
+	}
+}
@@ -59,6 +59,10 @@ type structSpec struct {
 	// ll is the line length for the struct
 	ll         int
 	fieldSpecs []fieldSpec
+
+	// rawLine, lineNumber, lineOffset, and lineTerminator are the indices of the fields
+	// that hold the original line, its line number, its offset, and its terminator, or -1.
+	rawLine, lineNumber, lineOffset, lineTerminator int
 }
 
 type fieldSpec struct {
@@ -83,11 +87,38 @@ func (s fieldSpec) getEncoder(useCodepointIndices bool) valueEncoder {
 
 func buildStructSpec(t reflect.Type) structSpec {
 	ss := structSpec{
-		fieldSpecs: make([]fieldSpec, t.NumField()),
+		fieldSpecs:     make([]fieldSpec, t.NumField()),
+		rawLine:        -1,
+		lineNumber:     -1,
+		lineOffset:     -1,
+		lineTerminator: -1,
 	}
 	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 
+		switch {
+		case isMetadataField(f, rawLineType):
+			if ss.rawLine < 0 {
+				ss.rawLine = i
+			}
+			continue
+		case isMetadataField(f, lineNumberType):
+			if ss.lineNumber < 0 {
+				ss.lineNumber = i
+			}
+			continue
+		case isMetadataField(f, lineOffsetType):
+			if ss.lineOffset < 0 {
+				ss.lineOffset = i
+			}
+			continue
+		case isMetadataField(f, lineTerminatorType):
+			if ss.lineTerminator < 0 {
+				ss.lineTerminator = i
+			}
+			continue
+		}
+
 		startPos, endPos, format, ok := parseTag(f.Tag.Get("fixed"))
 		if !ok {
 			continue
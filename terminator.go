@@ -0,0 +1,98 @@
+package fixedwidth
+
+import "bytes"
+
+// SetLineTerminators configures the Decoder to accept any of the given terminators at
+// the end of a line, such as "\r\n" and "\n" for files that mix both. When more than one
+// terminator matches at the same position, the longest is used. The first terminator
+// takes the place of the one set by SetLineTerminator, for example when records are
+// written by Sort.
+//
+// Use Terminator or a LineTerminator field to find which terminator ended a line.
+func (d *Decoder) SetLineTerminators(terminators ...[]byte) {
+	var ts [][]byte
+	for _, t := range terminators {
+		if len(t) > 0 {
+			ts = append(ts, t)
+		}
+	}
+	switch len(ts) {
+	case 0:
+		return
+	case 1:
+		d.SetLineTerminator(ts[0])
+		return
+	}
+	d.lineTerminator = ts[0]
+	d.lineTerminators = ts
+	d.terminatorStarts = [256]bool{}
+	for _, t := range ts {
+		d.terminatorStarts[t[0]] = true
+	}
+}
+
+// SetUniversalNewlines configures the Decoder to accept "\n", "\r\n", and "\r" as line
+// terminators. Setting it to false restores the default terminator of "\n".
+func (d *Decoder) SetUniversalNewlines(universal bool) {
+	if universal {
+		d.SetLineTerminators([]byte("\n"), []byte("\r\n"), []byte("\r"))
+	} else {
+		d.SetLineTerminator([]byte("\n"))
+	}
+}
+
+// Terminator returns the terminator of the line that was decoded last, or nil if the
+// line was not terminated, such as the last line of an input without a final
+// terminator or a record read with SetRecordLength. Writing it after the line when
+// encoding reproduces the line endings of the input:
+//
+//	err := d.Decode(&v)
+//	// ...
+//	err = e.Encode(v)
+//	// ...
+//	_, err = w.Write(d.Terminator())
+//
+// The result is not meaningful when decoding a slice, as every line of the input has
+// been read by the time Decode returns; use a LineTerminator field to get the
+// terminator of each value instead. The returned slice must not be modified.
+func (d *Decoder) Terminator() []byte {
+	if d.emitted != nil {
+		return d.emitted.Terminator
+	}
+	return d.terminator
+}
+
+// scanTerminators is the split function used when more than one line terminator is
+// accepted. data is not empty.
+func (d *Decoder) scanTerminators(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	for i := 0; i < len(data); i++ {
+		if !d.terminatorStarts[data[i]] {
+			continue
+		}
+
+		var match []byte
+		for _, t := range d.lineTerminators {
+			switch {
+			case bytes.HasPrefix(data[i:], t):
+				if len(t) > len(match) {
+					match = t
+				}
+			case !atEOF && bytes.HasPrefix(t, data[i:]):
+				// A terminator, or a longer one, might end past the end of data.
+				return 0, nil, nil
+			}
+		}
+		if match != nil {
+			d.scanTerminator = match
+			return i + len(match), data[:i], nil
+		}
+	}
+
+	// If we're at EOF, we have a final, non-terminated line. Return it.
+	if atEOF {
+		d.scanTerminator = nil
+		return len(data), data, nil
+	}
+	// Request more data.
+	return 0, nil, nil
+}
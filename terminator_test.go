@@ -0,0 +1,149 @@
+package fixedwidth
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecoder_SetLineTerminators(t *testing.T) {
+	type S struct {
+		Name string `fixed:"1,4,none"`
+	}
+
+	for _, tt := range []struct {
+		name      string
+		in        string
+		setup     func(d *Decoder)
+		want      []string
+		wantTerms []string
+	}{
+		{
+			name:      "universal",
+			in:        "ab\r\ncd\nef\rgh",
+			setup:     func(d *Decoder) { d.SetUniversalNewlines(true) },
+			want:      []string{"ab", "cd", "ef", "gh"},
+			wantTerms: []string{"\r\n", "\n", "\r", ""},
+		},
+		{
+			name:      "universal with empty lines",
+			in:        "ab\r\r\n\n\r\ncd\r",
+			setup:     func(d *Decoder) { d.SetUniversalNewlines(true) },
+			want:      []string{"ab", "", "", "", "cd"},
+			wantTerms: []string{"\r", "\r\n", "\n", "\r\n", "\r"},
+		},
+		{
+			name:      "set",
+			in:        "ab||cd\r\nef|g",
+			setup:     func(d *Decoder) { d.SetLineTerminators([]byte("||"), []byte("\r\n")) },
+			want:      []string{"ab", "cd", "ef|g"},
+			wantTerms: []string{"||", "\r\n", ""},
+		},
+		{
+			name:      "non-ASCII",
+			in:        "ab cd\nef",
+			setup:     func(d *Decoder) { d.SetLineTerminators([]byte(" "), []byte("\n")) },
+			want:      []string{"ab", "cd", "ef"},
+			wantTerms: []string{" ", "\n", ""},
+		},
+		{
+			name: "universal off",
+			in:   "ab\r\ncd\n",
+			setup: func(d *Decoder) {
+				d.SetUniversalNewlines(true)
+				d.SetUniversalNewlines(false)
+			},
+			want:      []string{"ab\r", "cd"},
+			wantTerms: []string{"\n", "\n"},
+		},
+	} {
+		for _, oneByte := range []bool{false, true} {
+			var r io.Reader = strings.NewReader(tt.in)
+			name := tt.name
+			if oneByte {
+				r = iotest.OneByteReader(r)
+				name += " one byte at a time"
+			}
+			t.Run(name, func(t *testing.T) {
+				d := NewDecoder(r)
+				tt.setup(d)
+				var have, haveTerms []string
+				for {
+					var s S
+					err := d.Decode(&s)
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						t.Fatalf("Decode() unexpected error: %v", err)
+					}
+					have = append(have, s.Name)
+					haveTerms = append(haveTerms, string(d.Terminator()))
+				}
+				if !reflect.DeepEqual(have, tt.want) {
+					t.Errorf("Decode() want %q, have %q", tt.want, have)
+				}
+				if !reflect.DeepEqual(haveTerms, tt.wantTerms) {
+					t.Errorf("Terminator() want %q, have %q", tt.wantTerms, haveTerms)
+				}
+			})
+		}
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		type S struct {
+			Name string `fixed:"1,2"`
+		}
+		const in = "ab\r\ncd\nef"
+		d := NewDecoder(strings.NewReader(in))
+		d.SetUniversalNewlines(true)
+		var out bytes.Buffer
+		e := NewEncoder(&out)
+		for {
+			var s S
+			if err := d.Decode(&s); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if err := e.Encode(s); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+			out.Write(d.Terminator())
+		}
+		if out.String() != in {
+			t.Errorf("round trip want %q, have %q", in, out.String())
+		}
+	})
+
+	t.Run("trailer", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("ab\r\ncd\nTT\r\n"))
+		d.SetUniversalNewlines(true)
+		d.SetSkipTrailer(1)
+		var s S
+		if err := d.Decode(&s); err != nil || string(d.Terminator()) != "\r\n" {
+			t.Fatalf("Decode() want terminator %q, have %q, %v", "\r\n", d.Terminator(), err)
+		}
+		if err := d.Decode(&s); err != nil || string(d.Terminator()) != "\n" {
+			t.Fatalf("Decode() want terminator %q, have %q, %v", "\n", d.Terminator(), err)
+		}
+		if err := d.Decode(&s); err != io.EOF {
+			t.Fatalf("Decode() want io.EOF, have %v", err)
+		}
+	})
+
+	t.Run("sort", func(t *testing.T) {
+		d := NewDecoder(strings.NewReader("b\r\na\n"))
+		d.SetUniversalNewlines(true)
+		var buf bytes.Buffer
+		if err := Sort(&buf, d, []SortKey{{Start: 1, End: 1}}, nil); err != nil {
+			t.Fatalf("Sort() unexpected error: %v", err)
+		}
+		if buf.String() != "a\nb\r\n" {
+			t.Errorf("Sort() want %q, have %q", "a\nb\r\n", buf.String())
+		}
+	})
+}